@@ -0,0 +1,206 @@
+package main
+
+import (
+	"image/color"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/driver/desktop"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// fileCell renders one cell of a file table. Name cells can switch to an
+// entry for inline renaming, every cell can be dragged to move or
+// transfer the entries of its row, and right-clicking it opens the
+// context menu of the row.
+type fileCell struct {
+	widget.BaseWidget
+	pane  *filePane
+	row   int
+	col   int
+	bg    *canvas.Rectangle
+	label *widget.Label
+	edit  *renameEntry
+}
+
+func newFileCell(pane *filePane) *fileCell {
+	cell := &fileCell{
+		pane:  pane,
+		row:   -1,
+		bg:    canvas.NewRectangle(color.Transparent),
+		label: widget.NewLabel("template"),
+		edit:  newRenameEntry(),
+	}
+	cell.label.Truncation = fyne.TextTruncateEllipsis
+	cell.edit.Hide()
+	cell.ExtendBaseWidget(cell)
+	return cell
+}
+
+func (c *fileCell) CreateRenderer() fyne.WidgetRenderer {
+	return widget.NewSimpleRenderer(container.NewStack(c.bg, c.label, c.edit))
+}
+
+// Dragged starts or continues dragging the entries of this row
+func (c *fileCell) Dragged(*fyne.DragEvent) {
+	if c.pane.drag != nil && c.row >= 0 {
+		c.pane.drag.start(c.pane, c.row)
+	}
+}
+
+// DragEnd drops the dragged entries on the row under the pointer
+func (c *fileCell) DragEnd() {
+	if c.pane.drag != nil {
+		c.pane.drag.end()
+	}
+}
+
+// TappedSecondary opens the context menu of this row
+func (c *fileCell) TappedSecondary(ev *fyne.PointEvent) {
+	c.pane.showContextMenu(c.row, ev.AbsolutePosition)
+}
+
+// MouseIn tracks the row under the pointer as a potential drop target
+func (c *fileCell) MouseIn(*desktop.MouseEvent) {
+	if c.pane.drag != nil {
+		c.pane.drag.hover(c.pane, c.row)
+	}
+}
+
+func (c *fileCell) MouseMoved(*desktop.MouseEvent) {}
+
+func (c *fileCell) MouseOut() {
+	if c.pane.drag != nil {
+		c.pane.drag.leave(c.pane, c.row)
+	}
+}
+
+// renameEntry is the inline rename editor. Enter commits and Escape or
+// losing focus cancels.
+type renameEntry struct {
+	widget.Entry
+	onCancel func()
+}
+
+func newRenameEntry() *renameEntry {
+	entry := &renameEntry{}
+	entry.ExtendBaseWidget(entry)
+	return entry
+}
+
+func (e *renameEntry) TypedKey(key *fyne.KeyEvent) {
+	if key.Name == fyne.KeyEscape {
+		if e.onCancel != nil {
+			e.onCancel()
+		}
+		return
+	}
+	e.Entry.TypedKey(key)
+}
+
+func (e *renameEntry) FocusLost() {
+	e.Entry.FocusLost()
+	if e.onCancel != nil {
+		e.onCancel()
+	}
+}
+
+// dragTracker follows entries dragged out of a file pane and the row under
+// the pointer, so the drop can be handed to the application.
+type dragTracker struct {
+	source    *filePane
+	entries   []fileEntry
+	hoverPane *filePane
+	hoverRow  int
+
+	// onDrop receives the dragged entries and the drop target. target is
+	// nil when the drop is not on a row of dst.
+	onDrop func(src *filePane, entries []fileEntry, dst *filePane, target *fileEntry)
+}
+
+func newDragTracker() *dragTracker {
+	return &dragTracker{hoverRow: -1}
+}
+
+// dragging reports whether entries are currently being dragged
+func (d *dragTracker) dragging() bool {
+	return d.source != nil
+}
+
+// start begins a drag from row of pane unless one is already in progress.
+// Dragging a selected row drags the whole selection.
+func (d *dragTracker) start(pane *filePane, row int) {
+	if d.dragging() || row >= len(pane.entries) {
+		return
+	}
+	if pane.sel.IsSelected(row) {
+		d.entries = pane.selectedEntries()
+	} else if !pane.entries[row].IsParent() {
+		d.entries = []fileEntry{pane.entries[row]}
+	}
+	if len(d.entries) > 0 {
+		d.source = pane
+	}
+}
+
+// isTarget reports whether row of pane should be highlighted as the drop target
+func (d *dragTracker) isTarget(pane *filePane, row int) bool {
+	return d.dragging() && d.hoverPane == pane && d.hoverRow == row
+}
+
+func (d *dragTracker) hover(pane *filePane, row int) {
+	previous := d.hoverPane
+	d.hoverPane, d.hoverRow = pane, row
+	if d.dragging() {
+		if previous != nil && previous != pane {
+			previous.table.Refresh()
+		}
+		pane.table.Refresh()
+	}
+}
+
+func (d *dragTracker) leave(pane *filePane, row int) {
+	if d.hoverPane == pane && d.hoverRow == row {
+		d.hoverRow = -1
+		if d.dragging() {
+			pane.table.Refresh()
+		}
+	}
+}
+
+func (d *dragTracker) end() {
+	if !d.dragging() {
+		return
+	}
+	src, entries, dst := d.source, d.entries, d.hoverPane
+	d.source, d.entries = nil, nil
+
+	src.table.Refresh()
+	if dst == nil {
+		return
+	}
+	dst.table.Refresh()
+
+	var target *fileEntry
+	if d.hoverRow >= 0 && d.hoverRow < len(dst.entries) {
+		entry := dst.entries[d.hoverRow]
+		target = &entry
+	}
+	if d.onDrop != nil {
+		d.onDrop(src, entries, dst, target)
+	}
+}
+
+// cellBackground returns the fill color of a cell in row of pane
+func cellBackground(pane *filePane, row int) color.Color {
+	switch {
+	case pane.drag != nil && pane.drag.isTarget(pane, row):
+		return theme.HoverColor()
+	case pane.sel.IsSelected(row):
+		return theme.SelectionColor()
+	default:
+		return color.Transparent
+	}
+}
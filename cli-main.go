@@ -5,15 +5,22 @@ package main
 
 import (
 	"bufio"
+	"context"
 	"fmt"
 	"io"
-	"log"
+	"net"
 	"os"
+	"os/exec"
+	"os/signal"
+	"path"
+	"sort"
 	"strconv"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/pkg/sftp"
+	"github.com/pmezard/go-difflib/difflib"
 	"golang.org/x/crypto/ssh"
 )
 
@@ -29,7 +36,13 @@ func NewSFTPClient() *SFTPClient {
 	}
 }
 
+// Connect connects with password authentication
 func (c *SFTPClient) Connect(host, username, password string, port int) error {
+	return c.ConnectContext(context.Background(), host, username, password, port)
+}
+
+// ConnectContext is Connect, stopping once ctx is done
+func (c *SFTPClient) ConnectContext(ctx context.Context, host, username, password string, port int) error {
 	config := &ssh.ClientConfig{
 		User: username,
 		Auth: []ssh.AuthMethod{
@@ -38,27 +51,16 @@ func (c *SFTPClient) Connect(host, username, password string, port int) error {
 		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
 		Timeout:         30 * time.Second,
 	}
-
-	addr := fmt.Sprintf("%s:%d", host, port)
-	sshClient, err := ssh.Dial("tcp", addr, config)
-	if err != nil {
-		return fmt.Errorf("failed to connect to SSH server: %v", err)
-	}
-
-	sftpClient, err := sftp.NewClient(sshClient)
-	if err != nil {
-		sshClient.Close()
-		return fmt.Errorf("failed to create SFTP client: %v", err)
-	}
-
-	c.sshClient = sshClient
-	c.sftpClient = sftpClient
-	c.connected = true
-
-	return nil
+	return c.dial(ctx, host, port, config)
 }
 
+// ConnectWithKey connects with key authentication
 func (c *SFTPClient) ConnectWithKey(host, username, keyPath string, port int) error {
+	return c.ConnectWithKeyContext(context.Background(), host, username, keyPath, port)
+}
+
+// ConnectWithKeyContext is ConnectWithKey, stopping once ctx is done
+func (c *SFTPClient) ConnectWithKeyContext(ctx context.Context, host, username, keyPath string, port int) error {
 	key, err := os.ReadFile(keyPath)
 	if err != nil {
 		return fmt.Errorf("unable to read private key: %v", err)
@@ -77,14 +79,31 @@ func (c *SFTPClient) ConnectWithKey(host, username, keyPath string, port int) er
 		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
 		Timeout:         30 * time.Second,
 	}
+	return c.dial(ctx, host, port, config)
+}
 
+// dial opens the SSH connection and the SFTP session on top of it. The
+// handshake is aborted by closing the network connection when ctx is done.
+func (c *SFTPClient) dial(ctx context.Context, host string, port int, config *ssh.ClientConfig) error {
 	addr := fmt.Sprintf("%s:%d", host, port)
-	sshClient, err := ssh.Dial("tcp", addr, config)
+	conn, err := (&net.Dialer{Timeout: config.Timeout}).DialContext(ctx, "tcp", addr)
 	if err != nil {
-		return fmt.Errorf("failed to connect to SSH server: %v", err)
+		return fmt.Errorf("failed to connect to SSH server: %v", cancelled(ctx, err))
 	}
 
+	stop := closeOnCancel(ctx, conn)
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, addr, config)
+	if err != nil {
+		stop()
+		conn.Close()
+		return fmt.Errorf("failed to connect to SSH server: %v", cancelled(ctx, err))
+	}
+	sshClient := ssh.NewClient(sshConn, chans, reqs)
+
 	sftpClient, err := sftp.NewClient(sshClient)
+	if !stop() {
+		err = ctx.Err()
+	}
 	if err != nil {
 		sshClient.Close()
 		return fmt.Errorf("failed to create SFTP client: %v", err)
@@ -97,6 +116,95 @@ func (c *SFTPClient) ConnectWithKey(host, username, keyPath string, port int) er
 	return nil
 }
 
+// closeOnCancel closes c as soon as ctx is done, so that a read or write
+// blocked on a hung server returns. Calling stop ends the watch; it
+// reports false when c was already closed.
+func closeOnCancel(ctx context.Context, c io.Closer) (stop func() bool) {
+	return context.AfterFunc(ctx, func() { c.Close() })
+}
+
+// cancelled returns the error of ctx in place of err once ctx is done,
+// since closing a file or connection surfaces as an unrelated error
+func cancelled(ctx context.Context, err error) error {
+	if err != nil && ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+// cleanupTimeout bounds how long removing the partial file of a
+// cancelled upload may take
+const cleanupTimeout = 10 * time.Second
+
+// openContext runs open, giving up once ctx is done. The SFTP request
+// cannot be interrupted, so a file that opens after that is closed in the
+// background, once abandoned ran if it is set.
+func openContext(ctx context.Context, open func() (*sftp.File, error), abandoned func()) (*sftp.File, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	type opened struct {
+		file *sftp.File
+		err  error
+	}
+	result := make(chan opened, 1)
+	go func() {
+		file, err := open()
+		result <- opened{file, err}
+	}()
+
+	select {
+	case r := <-result:
+		return r.file, r.err
+	case <-ctx.Done():
+		go func() {
+			if r := <-result; r.err == nil {
+				if abandoned != nil {
+					abandoned()
+				}
+				r.file.Close()
+			}
+		}()
+		return nil, ctx.Err()
+	}
+}
+
+// callContext runs call, giving up once ctx is done. The SFTP request
+// cannot be interrupted, so the server may still carry it out.
+func callContext(ctx context.Context, call func() error) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	result := make(chan error, 1)
+	go func() { result <- call() }()
+
+	select {
+	case err := <-result:
+		return err
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// removePartial removes the partial file of a cancelled upload in the
+// background. The connection may be hung, which is often why the upload
+// was cancelled, so the file is removed over an SFTP session of its own
+// that is closed after cleanupTimeout.
+func (c *SFTPClient) removePartial(remotePath string) {
+	sshClient := c.sshClient
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+		defer cancel()
+		cleanup, err := sftp.NewClient(sshClient)
+		if err != nil {
+			return
+		}
+		defer cleanup.Close()
+		defer closeOnCancel(ctx, cleanup)()
+		cleanup.Remove(remotePath)
+	}()
+}
+
 func (c *SFTPClient) Disconnect() error {
 	if !c.connected {
 		return nil
@@ -117,14 +225,20 @@ func (c *SFTPClient) IsConnected() bool {
 	return c.connected
 }
 
-func (c *SFTPClient) ListDirectory(remotePath string) error {
+// ListDirectory prints the entries of remotePath
+func (c *SFTPClient) ListDirectory(remotePath string, showAll bool) error {
+	return c.ListDirectoryContext(context.Background(), remotePath, showAll)
+}
+
+// ListDirectoryContext is ListDirectory, stopping once ctx is done
+func (c *SFTPClient) ListDirectoryContext(ctx context.Context, remotePath string, showAll bool) error {
 	if !c.connected {
 		return fmt.Errorf("not connected to server")
 	}
 
-	files, err := c.sftpClient.ReadDir(remotePath)
+	files, err := c.sftpClient.ReadDirContext(ctx, remotePath)
 	if err != nil {
-		return fmt.Errorf("failed to list directory: %v", err)
+		return fmt.Errorf("failed to list directory: %v", cancelled(ctx, err))
 	}
 
 	fmt.Printf("\nListing directory: %s\n", remotePath)
@@ -132,22 +246,46 @@ func (c *SFTPClient) ListDirectory(remotePath string) error {
 	fmt.Println("----\t----\t\t--------\t\t----")
 
 	for _, file := range files {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if !showAll && strings.HasPrefix(file.Name(), ".") {
+			continue
+		}
+
 		fileType := "FILE"
+		name := file.Name()
 		if file.IsDir() {
 			fileType = "DIR "
+		} else if file.Mode()&os.ModeSymlink != 0 {
+			fileType = "LINK"
+			linkPath := path.Join(remotePath, file.Name())
+			if target, err := c.sftpClient.ReadLink(linkPath); err == nil {
+				name += " -> " + target
+			}
+			if _, err := c.sftpClient.Stat(linkPath); err != nil {
+				name += " (broken)"
+			}
 		}
 
 		fmt.Printf("%s\t%-10d\t%s\t%s\n",
 			fileType,
 			file.Size(),
 			file.ModTime().Format("2006-01-02 15:04:05"),
-			file.Name())
+			name)
 	}
 
 	return nil
 }
 
+// UploadFile copies a local file to remotePath
 func (c *SFTPClient) UploadFile(localPath, remotePath string) error {
+	return c.UploadFileContext(context.Background(), localPath, remotePath)
+}
+
+// UploadFileContext copies a local file to remotePath. When ctx is done the
+// remote file is closed and the partial upload removed in the background.
+func (c *SFTPClient) UploadFileContext(ctx context.Context, localPath, remotePath string) error {
 	if !c.connected {
 		return fmt.Errorf("not connected to server")
 	}
@@ -158,13 +296,23 @@ func (c *SFTPClient) UploadFile(localPath, remotePath string) error {
 	}
 	defer localFile.Close()
 
-	remoteFile, err := c.sftpClient.Create(remotePath)
+	remoteFile, err := openContext(ctx, func() (*sftp.File, error) {
+		return c.sftpClient.Create(remotePath)
+	}, func() { c.removePartial(remotePath) })
 	if err != nil {
 		return fmt.Errorf("failed to create remote file: %v", err)
 	}
-	defer remoteFile.Close()
-
+	stop := closeOnCancel(ctx, remoteFile)
 	_, err = io.Copy(remoteFile, localFile)
+	// A file closed on cancel is not closed again, as that would wait for
+	// the reply to the first close
+	if !stop() {
+		c.removePartial(remotePath)
+		return fmt.Errorf("failed to upload file: %v", ctx.Err())
+	}
+	if closeErr := remoteFile.Close(); err == nil {
+		err = closeErr
+	}
 	if err != nil {
 		return fmt.Errorf("failed to upload file: %v", err)
 	}
@@ -173,24 +321,42 @@ func (c *SFTPClient) UploadFile(localPath, remotePath string) error {
 	return nil
 }
 
+// DownloadFile copies remotePath to a local file
 func (c *SFTPClient) DownloadFile(remotePath, localPath string) error {
+	return c.DownloadFileContext(context.Background(), remotePath, localPath)
+}
+
+// DownloadFileContext copies remotePath to a local file. When ctx is done the
+// remote file is closed and the partial download removed.
+func (c *SFTPClient) DownloadFileContext(ctx context.Context, remotePath, localPath string) error {
 	if !c.connected {
 		return fmt.Errorf("not connected to server")
 	}
 
-	remoteFile, err := c.sftpClient.Open(remotePath)
+	remoteFile, err := openContext(ctx, func() (*sftp.File, error) {
+		return c.sftpClient.Open(remotePath)
+	}, nil)
 	if err != nil {
 		return fmt.Errorf("failed to open remote file: %v", err)
 	}
-	defer remoteFile.Close()
+	stop := closeOnCancel(ctx, remoteFile)
 
 	localFile, err := os.Create(localPath)
 	if err != nil {
+		if stop() {
+			remoteFile.Close()
+		}
 		return fmt.Errorf("failed to create local file: %v", err)
 	}
-	defer localFile.Close()
-
 	_, err = io.Copy(localFile, remoteFile)
+	if closeErr := localFile.Close(); err == nil {
+		err = closeErr
+	}
+	if !stop() {
+		os.Remove(localPath)
+		return fmt.Errorf("failed to download file: %v", ctx.Err())
+	}
+	remoteFile.Close()
 	if err != nil {
 		return fmt.Errorf("failed to download file: %v", err)
 	}
@@ -241,6 +407,480 @@ func (c *SFTPClient) RemoveDirectory(remotePath string) error {
 	return nil
 }
 
+// Recursive deletes above either threshold must be confirmed by typing
+// the directory name
+const (
+	confirmDeleteItems = 100
+	confirmDeleteBytes = 100 << 20
+)
+
+// ScanTree counts the directories, files and bytes below remotePath
+func (c *SFTPClient) ScanTree(remotePath string) (dirs, files int, bytes int64, err error) {
+	return c.ScanTreeContext(context.Background(), remotePath)
+}
+
+// ScanTreeContext counts the directories, files and bytes below remotePath,
+// stopping once ctx is done
+func (c *SFTPClient) ScanTreeContext(ctx context.Context, remotePath string) (dirs, files int, bytes int64, err error) {
+	if !c.connected {
+		return 0, 0, 0, fmt.Errorf("not connected to server")
+	}
+
+	walker := c.sftpClient.Walk(remotePath)
+	for walker.Step() {
+		if ctx.Err() != nil {
+			return 0, 0, 0, fmt.Errorf("failed to scan directory: %v", ctx.Err())
+		}
+		info := walker.Stat()
+		if info == nil {
+			if walker.Path() == remotePath {
+				return 0, 0, 0, fmt.Errorf("failed to scan directory: %v", walker.Err())
+			}
+			continue
+		}
+		if info.IsDir() {
+			dirs++
+		} else {
+			files++
+			bytes += info.Size()
+		}
+	}
+	return dirs, files, bytes, nil
+}
+
+// FreeSpace returns the bytes available to the user and the total size
+// of the filesystem holding remotePath
+func (c *SFTPClient) FreeSpace(remotePath string) (free, total uint64, err error) {
+	return c.FreeSpaceContext(context.Background(), remotePath)
+}
+
+// FreeSpaceContext is FreeSpace, giving up once ctx is done
+func (c *SFTPClient) FreeSpaceContext(ctx context.Context, remotePath string) (free, total uint64, err error) {
+	if !c.connected {
+		return 0, 0, fmt.Errorf("not connected to server")
+	}
+
+	var stat *sftp.StatVFS
+	err = callContext(ctx, func() (err error) {
+		stat, err = c.sftpClient.StatVFS(remotePath)
+		return err
+	})
+	if ctx.Err() != nil {
+		return 0, 0, ctx.Err()
+	}
+	if err != nil {
+		return 0, 0, fmt.Errorf("server does not report disk space: %v", err)
+	}
+	return stat.Frsize * stat.Bavail, stat.TotalSpace(), nil
+}
+
+// DiskFree prints the disk usage of the filesystem holding remotePath
+func (c *SFTPClient) DiskFree(remotePath string) error {
+	return c.DiskFreeContext(context.Background(), remotePath)
+}
+
+// DiskFreeContext is DiskFree, giving up once ctx is done
+func (c *SFTPClient) DiskFreeContext(ctx context.Context, remotePath string) error {
+	free, total, err := c.FreeSpaceContext(ctx, remotePath)
+	if err != nil {
+		return err
+	}
+
+	used := total - free
+	percent := 0.0
+	if total > 0 {
+		percent = float64(used) * 100 / float64(total)
+	}
+	fmt.Printf("%-12s %-12s %-12s %s\n", "Size", "Used", "Available", "Use%")
+	fmt.Printf("%-12s %-12s %-12s %.0f%%\n", formatBytes(total), formatBytes(used), formatBytes(free), percent)
+	return nil
+}
+
+// formatBytes formats a byte count using binary units
+func formatBytes(size uint64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := uint64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+// RemoveTree deletes remotePath and everything below it
+func (c *SFTPClient) RemoveTree(remotePath string) error {
+	return c.RemoveTreeContext(context.Background(), remotePath)
+}
+
+// RemoveTreeContext deletes remotePath and everything below it, children before
+// their parents. Entries that cannot be deleted are reported and skipped,
+// and the delete stops once ctx is done.
+func (c *SFTPClient) RemoveTreeContext(ctx context.Context, remotePath string) error {
+	if !c.connected {
+		return fmt.Errorf("not connected to server")
+	}
+
+	type item struct {
+		path  string
+		isDir bool
+	}
+	var items []item
+	walker := c.sftpClient.Walk(remotePath)
+	for walker.Step() {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if info := walker.Stat(); info != nil {
+			items = append(items, item{walker.Path(), info.IsDir()})
+		}
+	}
+
+	failed := 0
+	for i := len(items) - 1; i >= 0; i-- {
+		if ctx.Err() != nil {
+			fmt.Printf("Stopped after removing %d of %d entries\n", len(items)-1-i-failed, len(items))
+			return ctx.Err()
+		}
+		var err error
+		if items[i].isDir {
+			err = c.sftpClient.RemoveDirectory(items[i].path)
+		} else {
+			err = c.sftpClient.Remove(items[i].path)
+		}
+		if err != nil {
+			fmt.Printf("  %s: %v\n", items[i].path, err)
+			failed++
+		}
+	}
+	if failed > 0 {
+		return fmt.Errorf("%d of %d entries could not be deleted", failed, len(items))
+	}
+
+	fmt.Printf("Successfully removed %s and %d entries below it\n", remotePath, len(items)-1)
+	return nil
+}
+
+func (c *SFTPClient) RenameFile(oldPath, newPath string, overwrite bool) error {
+	return c.RenameFileContext(context.Background(), oldPath, newPath, overwrite)
+}
+
+// RenameFileContext is RenameFile, giving up once ctx is done. An
+// existing file is only replaced while ctx is not done.
+func (c *SFTPClient) RenameFileContext(ctx context.Context, oldPath, newPath string, overwrite bool) error {
+	if !c.connected {
+		return fmt.Errorf("not connected to server")
+	}
+
+	var info os.FileInfo
+	err := callContext(ctx, func() (err error) {
+		info, err = c.sftpClient.Lstat(newPath)
+		return err
+	})
+	if ctx.Err() != nil {
+		return fmt.Errorf("failed to rename: %v", ctx.Err())
+	}
+	exists := err == nil
+	// Replacing a folder would delete what it holds, even with -f
+	if exists && info.IsDir() {
+		return fmt.Errorf("%s is a folder and cannot be replaced", newPath)
+	}
+	if exists && !overwrite {
+		return fmt.Errorf("%s already exists (use -f to overwrite)", newPath)
+	}
+
+	if _, ok := c.sftpClient.HasExtension("posix-rename@openssh.com"); ok {
+		err = callContext(ctx, func() error { return c.sftpClient.PosixRename(oldPath, newPath) })
+	} else {
+		if exists {
+			if err := callContext(ctx, func() error { return c.sftpClient.Remove(newPath) }); err != nil {
+				return fmt.Errorf("failed to replace %s: %v", newPath, err)
+			}
+		}
+		err = callContext(ctx, func() error { return c.sftpClient.Rename(oldPath, newPath) })
+	}
+	if err != nil {
+		return fmt.Errorf("failed to rename: %v", err)
+	}
+
+	fmt.Printf("Successfully renamed %s to %s\n", oldPath, newPath)
+	return nil
+}
+
+// ChangeMode sets the permission bits of remotePath, recursively when
+// recursive is set
+func (c *SFTPClient) ChangeMode(remotePath string, mode os.FileMode, recursive bool) error {
+	return c.ChangeModeContext(context.Background(), remotePath, mode, recursive)
+}
+
+// ChangeModeContext sets the permission bits of remotePath, and of everything
+// below it when recursive is set. Like the X of chmod, a recursive change
+// only gives execute bits to folders and to files already executable.
+func (c *SFTPClient) ChangeModeContext(ctx context.Context, remotePath string, mode os.FileMode, recursive bool) error {
+	if !c.connected {
+		return fmt.Errorf("not connected to server")
+	}
+
+	err := c.applyToTree(ctx, remotePath, recursive, func(p string, info os.FileInfo) error {
+		if recursive && !info.IsDir() && info.Mode()&0111 == 0 {
+			return c.sftpClient.Chmod(p, mode&^0111)
+		}
+		return c.sftpClient.Chmod(p, mode)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to change mode: %v", err)
+	}
+
+	fmt.Printf("Successfully changed mode of %s to %04o\n", remotePath, uint32(mode.Perm())|specialModeBits(mode))
+	return nil
+}
+
+// ChangeOwner sets the owner and group of remotePath, recursively when
+// recursive is set
+func (c *SFTPClient) ChangeOwner(remotePath string, uid, gid int, recursive bool) error {
+	return c.ChangeOwnerContext(context.Background(), remotePath, uid, gid, recursive)
+}
+
+// ChangeOwnerContext sets the owner and group of remotePath, and of everything
+// below it when recursive is set. A uid or gid of -1 keeps the current value.
+func (c *SFTPClient) ChangeOwnerContext(ctx context.Context, remotePath string, uid, gid int, recursive bool) error {
+	if !c.connected {
+		return fmt.Errorf("not connected to server")
+	}
+
+	err := c.applyToTree(ctx, remotePath, recursive, func(p string, info os.FileInfo) error {
+		newUID, newGID := uid, gid
+		if stat, ok := info.Sys().(*sftp.FileStat); ok {
+			if newUID < 0 {
+				newUID = int(stat.UID)
+			}
+			if newGID < 0 {
+				newGID = int(stat.GID)
+			}
+		}
+		if newUID < 0 || newGID < 0 {
+			return fmt.Errorf("cannot read current owner of %s", p)
+		}
+		return c.sftpClient.Chown(p, newUID, newGID)
+	})
+	if err != nil {
+		return fmt.Errorf("failed to change owner: %v", err)
+	}
+
+	fmt.Printf("Successfully changed owner of %s\n", remotePath)
+	return nil
+}
+
+// applyToTree calls apply for remotePath and, when recursive is set, for
+// every entry below it except symlinks, stopping once ctx is done
+func (c *SFTPClient) applyToTree(ctx context.Context, remotePath string, recursive bool, apply func(string, os.FileInfo) error) error {
+	if !recursive {
+		info, err := c.sftpClient.Lstat(remotePath)
+		if err != nil {
+			return err
+		}
+		return apply(remotePath, info)
+	}
+
+	failed := 0
+	var firstErr error
+	walker := c.sftpClient.Walk(remotePath)
+	for walker.Step() {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		err := walker.Err()
+		if err == nil {
+			if walker.Path() != remotePath && walker.Stat().Mode()&os.ModeSymlink != 0 {
+				continue
+			}
+			err = apply(walker.Path(), walker.Stat())
+		}
+		if err != nil {
+			fmt.Printf("  %s: %v\n", walker.Path(), err)
+			failed++
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d entries failed, first: %v", failed, firstErr)
+	}
+	return nil
+}
+
+// parseMode parses an octal mode such as "755" or "4755"
+func parseMode(text string) (os.FileMode, error) {
+	value, err := strconv.ParseUint(text, 8, 32)
+	if err != nil || value > 07777 {
+		return 0, fmt.Errorf("invalid octal mode: %s", text)
+	}
+
+	mode := os.FileMode(value) & os.ModePerm
+	if value&04000 != 0 {
+		mode |= os.ModeSetuid
+	}
+	if value&02000 != 0 {
+		mode |= os.ModeSetgid
+	}
+	if value&01000 != 0 {
+		mode |= os.ModeSticky
+	}
+	return mode, nil
+}
+
+// specialModeBits returns the octal setuid, setgid and sticky bits of mode
+func specialModeBits(mode os.FileMode) uint32 {
+	var bits uint32
+	if mode&os.ModeSetuid != 0 {
+		bits |= 04000
+	}
+	if mode&os.ModeSetgid != 0 {
+		bits |= 02000
+	}
+	if mode&os.ModeSticky != 0 {
+		bits |= 01000
+	}
+	return bits
+}
+
+// parseOwner parses "uid", "uid:gid" or ":gid". Missing parts are -1.
+func parseOwner(text string) (int, int, error) {
+	uid, gid := -1, -1
+	uidText, gidText, hasGroup := strings.Cut(text, ":")
+	var err error
+	if uidText != "" {
+		if uid, err = strconv.Atoi(uidText); err != nil {
+			return 0, 0, fmt.Errorf("invalid uid: %s", uidText)
+		}
+	}
+	if hasGroup && gidText != "" {
+		if gid, err = strconv.Atoi(gidText); err != nil {
+			return 0, 0, fmt.Errorf("invalid gid: %s", gidText)
+		}
+	}
+	if uid < 0 && gid < 0 {
+		return 0, 0, fmt.Errorf("invalid owner: %s", text)
+	}
+	return uid, gid, nil
+}
+
+// recursiveFlag strips a leading -R from args
+func recursiveFlag(args []string) ([]string, bool) {
+	if len(args) > 0 && args[0] == "-R" {
+		return args[1:], true
+	}
+	return args, false
+}
+
+// CreateSymlink creates a symbolic link at linkPath pointing to target
+func (c *SFTPClient) CreateSymlink(target, linkPath string) error {
+	return c.CreateSymlinkContext(context.Background(), target, linkPath)
+}
+
+// CreateSymlinkContext is CreateSymlink, giving up once ctx is done
+func (c *SFTPClient) CreateSymlinkContext(ctx context.Context, target, linkPath string) error {
+	if !c.connected {
+		return fmt.Errorf("not connected to server")
+	}
+
+	err := callContext(ctx, func() error {
+		_, err := c.sftpClient.Lstat(linkPath)
+		return err
+	})
+	if ctx.Err() != nil {
+		return fmt.Errorf("failed to create symlink: %v", ctx.Err())
+	}
+	if err == nil {
+		return fmt.Errorf("%s already exists", linkPath)
+	}
+
+	err = callContext(ctx, func() error { return c.sftpClient.Symlink(target, linkPath) })
+	if err != nil {
+		return fmt.Errorf("failed to create symlink: %v", err)
+	}
+
+	fmt.Printf("Successfully created symlink %s -> %s\n", linkPath, target)
+	return nil
+}
+
+// ReadLink returns the target of a symbolic link
+func (c *SFTPClient) ReadLink(linkPath string) (string, error) {
+	if !c.connected {
+		return "", fmt.Errorf("not connected to server")
+	}
+
+	target, err := c.sftpClient.ReadLink(linkPath)
+	if err != nil {
+		return "", fmt.Errorf("failed to read link: %v", err)
+	}
+
+	return target, nil
+}
+
+// Diff prints a unified diff between two remote files, or between a
+// local and a remote file when local is set
+func (c *SFTPClient) Diff(fromPath, toPath string, local bool) error {
+	return c.DiffContext(context.Background(), fromPath, toPath, local)
+}
+
+// DiffContext prints a unified diff between two remote files, or between a local
+// and a remote file when local is set
+func (c *SFTPClient) DiffContext(ctx context.Context, fromPath, toPath string, local bool) error {
+	if !c.connected {
+		return fmt.Errorf("not connected to server")
+	}
+
+	var from []byte
+	var err error
+	if local {
+		from, err = os.ReadFile(fromPath)
+	} else {
+		from, err = c.readRemote(ctx, fromPath)
+	}
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %v", fromPath, err)
+	}
+	to, err := c.readRemote(ctx, toPath)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %v", toPath, err)
+	}
+
+	diff, err := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(string(from)),
+		B:        difflib.SplitLines(string(to)),
+		FromFile: fromPath,
+		ToFile:   toPath,
+		Context:  3,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to compare files: %v", err)
+	}
+
+	if diff == "" {
+		fmt.Println("Files are identical")
+	} else {
+		fmt.Print(diff)
+	}
+	return nil
+}
+
+func (c *SFTPClient) readRemote(ctx context.Context, remotePath string) ([]byte, error) {
+	file, err := c.sftpClient.Open(remotePath)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	defer closeOnCancel(ctx, file)()
+
+	data, err := io.ReadAll(file)
+	return data, cancelled(ctx, err)
+}
+
 func (c *SFTPClient) GetWorkingDirectory() (string, error) {
 	if !c.connected {
 		return "", fmt.Errorf("not connected to server")
@@ -254,50 +894,347 @@ func (c *SFTPClient) GetWorkingDirectory() (string, error) {
 	return wd, nil
 }
 
+// Tab completion limits
+const (
+	// completionCacheTTL is how long listings used for Tab completion are reused
+	completionCacheTTL = 10 * time.Second
+	// completionTimeout is how long Tab waits for the server, as Ctrl+C
+	// does not interrupt it while a line is edited
+	completionTimeout = 5 * time.Second
+)
+
+type cachedListing struct {
+	entries []os.FileInfo
+	at      time.Time
+}
+
+// remoteCompleter completes remote paths on the command line from cached
+// directory listings
+type remoteCompleter struct {
+	client *SFTPClient
+	cache  map[string]cachedListing
+}
+
+func newRemoteCompleter(client *SFTPClient) *remoteCompleter {
+	return &remoteCompleter{client: client, cache: make(map[string]cachedListing)}
+}
+
+// remotePathCommands lists the commands taking remote paths, and whether
+// those are folders only
+var remotePathCommands = map[string]bool{
+	"ls": true, "df": true, "du": true, "mkdir": true, "rmdir": true,
+	"upload": false, "download": false, "delete": false, "rename": false, "mv": false,
+	"ln": false, "readlink": false, "diff": false, "chmod": false, "chown": false, "chgrp": false,
+}
+
+// list returns the entries of a remote folder, reusing recent listings
+func (r *remoteCompleter) list(ctx context.Context, dir string) []os.FileInfo {
+	if listing, ok := r.cache[dir]; ok && time.Since(listing.at) < completionCacheTTL {
+		return listing.entries
+	}
+	entries, err := r.client.sftpClient.ReadDirContext(ctx, dir)
+	if err != nil {
+		return nil
+	}
+	r.cache[dir] = cachedListing{entries: entries, at: time.Now()}
+	return entries
+}
+
+// complete returns the remote paths starting with word. Folders end with
+// a slash and hidden entries are only offered once a dot has been typed.
+// It gives up on the server once ctx is done.
+func (r *remoteCompleter) complete(ctx context.Context, word string, dirsOnly bool) []string {
+	dirPart, prefix := "", word
+	if i := strings.LastIndex(word, "/"); i >= 0 {
+		dirPart, prefix = word[:i+1], word[i+1:]
+	}
+	dir := "."
+	if dirPart != "" {
+		dir = path.Clean(dirPart)
+	}
+
+	var options []string
+	for _, info := range r.list(ctx, dir) {
+		if ctx.Err() != nil {
+			return nil
+		}
+		name := info.Name()
+		if !strings.HasPrefix(name, prefix) || (strings.HasPrefix(name, ".") && !strings.HasPrefix(prefix, ".")) {
+			continue
+		}
+		isDir := info.IsDir()
+		if info.Mode()&os.ModeSymlink != 0 {
+			if target, err := r.client.sftpClient.Stat(path.Join(dir, name)); err == nil {
+				isDir = target.IsDir()
+			}
+		}
+		if isDir {
+			options = append(options, dirPart+name+"/")
+		} else if !dirsOnly {
+			options = append(options, dirPart+name)
+		}
+	}
+	sort.Strings(options)
+	return options
+}
+
+// completeLine returns the word being typed at the end of line and its
+// completions when it is a remote path argument
+func (r *remoteCompleter) completeLine(line string) (string, []string) {
+	fields := strings.Fields(line)
+	word := ""
+	if len(fields) > 0 && !strings.HasSuffix(line, " ") {
+		word = fields[len(fields)-1]
+		fields = fields[:len(fields)-1]
+	}
+	if len(fields) == 0 || !r.client.IsConnected() {
+		return word, nil
+	}
+
+	command := strings.ToLower(fields[0])
+	dirsOnly, ok := remotePathCommands[command]
+	if !ok || strings.HasPrefix(word, "-") {
+		return word, nil
+	}
+	var args []string
+	for _, arg := range fields[1:] {
+		if !strings.HasPrefix(arg, "-") {
+			args = append(args, arg)
+		}
+	}
+	// Skip the local file arguments
+	switch {
+	case command == "upload" && len(args) == 0,
+		command == "download" && len(args) == 1,
+		command == "diff" && len(args) == 0 && strings.Contains(line, " -l "):
+		return word, nil
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), completionTimeout)
+	defer cancel()
+	return word, r.complete(ctx, word, dirsOnly)
+}
+
+// lineReader reads command lines. On terminals it reads key by key, with
+// the terminal switched to character mode through stty, so that Tab can
+// complete remote paths. Elsewhere, for example on Windows or with piped
+// input, it reads plain lines.
+type lineReader struct {
+	in       *bufio.Reader
+	terminal bool
+}
+
+func newLineReader(f *os.File) *lineReader {
+	info, err := f.Stat()
+	return &lineReader{
+		in:       bufio.NewReader(f),
+		terminal: err == nil && info.Mode()&os.ModeCharDevice != 0,
+	}
+}
+
+// stty runs stty on the terminal of stdin and returns its output
+func stty(args ...string) (string, error) {
+	cmd := exec.Command("stty", args...)
+	cmd.Stdin = os.Stdin
+	out, err := cmd.Output()
+	return strings.TrimSpace(string(out)), err
+}
+
+// readLine prints prompt and reads a line. complete returns the word at
+// the end of the line and its completions, and may be nil. ok is false
+// at the end of the input.
+func (r *lineReader) readLine(prompt string, complete func(line string) (string, []string)) (line string, ok bool) {
+	fmt.Print(prompt)
+	if r.terminal {
+		saved, err := stty("-g")
+		if err == nil {
+			_, err = stty("-icanon", "-echo", "-isig", "min", "1")
+		}
+		if err == nil {
+			defer stty(saved)
+			return r.editLine(prompt, complete)
+		}
+		// No stty, keep reading plain lines
+		r.terminal = false
+	}
+
+	line, err := r.in.ReadString('\n')
+	if err != nil && line == "" {
+		return "", false
+	}
+	return strings.TrimRight(line, "\r\n"), true
+}
+
+// editLine reads a line key by key, echoing it and completing on Tab.
+// Ctrl+C discards the line and Ctrl+D on an empty line ends the input.
+func (r *lineReader) editLine(prompt string, complete func(line string) (string, []string)) (string, bool) {
+	var line []byte
+	for {
+		b, err := r.in.ReadByte()
+		if err != nil {
+			fmt.Println()
+			return string(line), len(line) > 0
+		}
+
+		switch {
+		case b == '\r' || b == '\n':
+			fmt.Println()
+			return string(line), true
+		case b == 3:
+			fmt.Println("^C")
+			return "", true
+		case b == 4:
+			if len(line) == 0 {
+				fmt.Println()
+				return "", false
+			}
+		case b == 127 || b == '\b':
+			if len(line) > 0 {
+				_, size := utf8.DecodeLastRune(line)
+				line = line[:len(line)-size]
+				fmt.Print("\b \b")
+			}
+		case b == '\t':
+			if complete != nil {
+				line = completeWord(prompt, line, complete)
+			}
+		case b == 27:
+			// Skip escape sequences such as the arrow keys
+			if next, err := r.in.ReadByte(); err == nil && next == '[' {
+				for {
+					c, err := r.in.ReadByte()
+					if err != nil || (c >= 0x40 && c <= 0x7e) {
+						break
+					}
+				}
+			}
+		case b < 32:
+		default:
+			line = append(line, b)
+			os.Stdout.Write([]byte{b})
+		}
+	}
+}
+
+// completeWord completes the word at the end of line like a shell: a
+// single match is inserted, several are completed to their common prefix
+// or listed when that adds nothing
+func completeWord(prompt string, line []byte, complete func(line string) (string, []string)) []byte {
+	word, options := complete(string(line))
+	if len(options) == 0 {
+		return line
+	}
+
+	completion := options[0]
+	if len(options) > 1 {
+		completion = commonPrefix(options)
+		if completion == word {
+			fmt.Printf("\n%s\n%s%s", strings.Join(options, "  "), prompt, line)
+			return line
+		}
+	} else if !strings.HasSuffix(completion, "/") {
+		completion += " "
+	}
+
+	added := strings.TrimPrefix(completion, word)
+	fmt.Print(added)
+	return append(line, added...)
+}
+
+// commonPrefix returns the longest prefix shared by all words
+func commonPrefix(words []string) string {
+	prefix := words[0]
+	for _, word := range words[1:] {
+		for !strings.HasPrefix(word, prefix) {
+			_, size := utf8.DecodeLastRuneInString(prefix)
+			prefix = prefix[:len(prefix)-size]
+		}
+	}
+	return prefix
+}
+
 func printHelp() {
 	fmt.Println("\nAvailable commands:")
 	fmt.Println("  connect <host> <username> <password> [port] - Connect using password authentication")
 	fmt.Println("  connectkey <host> <username> <keypath> [port] - Connect using SSH key authentication")
 	fmt.Println("  disconnect - Disconnect from server")
-	fmt.Println("  ls [path] - List directory contents")
+	fmt.Println("  ls [-a] [path] - List directory contents (-a includes hidden files)")
 	fmt.Println("  pwd - Print working directory")
+	fmt.Println("  df [path] - Show free and total disk space on server")
+	fmt.Println("  du [path] - Calculate the total size of a directory on server")
 	fmt.Println("  upload <local_file> <remote_file> - Upload file to server")
 	fmt.Println("  download <remote_file> <local_file> - Download file from server")
 	fmt.Println("  delete <remote_file> - Delete file on server")
 	fmt.Println("  mkdir <remote_directory> - Create directory on server")
-	fmt.Println("  rmdir <remote_directory> - Remove directory on server")
+	fmt.Println("  rmdir [-r] <remote_directory> - Remove directory on server (-r removes its contents too)")
+	fmt.Println("  rename [-f] <old_path> <new_path> - Rename or move on server (alias: mv)")
+	fmt.Println("  ln -s <target> <link_path> - Create symbolic link on server")
+	fmt.Println("  readlink <link_path> - Print the target of a symbolic link")
+	fmt.Println("  diff [-l] <file_a> <remote_file_b> - Show a unified diff (-l reads file_a locally)")
+	fmt.Println("  chmod [-R] <octal_mode> <remote_path> - Change permissions on server")
+	fmt.Println("  chown [-R] <uid>[:<gid>] <remote_path> - Change owner and group on server")
+	fmt.Println("  chgrp [-R] <gid> <remote_path> - Change group on server")
 	fmt.Println("  help - Show this help message")
 	fmt.Println("  quit - Exit the application")
+	fmt.Println("\nPress Ctrl+C to cancel the running command.")
+}
+
+// commandContext returns the context of one command, cancelled when the
+// user presses Ctrl+C while it runs. Interrupts left over from before the
+// command are dropped.
+func commandContext(interrupts <-chan os.Signal) (context.Context, context.CancelFunc) {
+	for len(interrupts) > 0 {
+		<-interrupts
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	go func() {
+		select {
+		case <-interrupts:
+			fmt.Println("\nCancelling...")
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	return ctx, cancel
 }
 
 func main() {
 	client := NewSFTPClient()
 	defer client.Disconnect()
 
-	scanner := bufio.NewScanner(os.Stdin)
+	// Ctrl+C cancels the running command instead of ending the client
+	interrupts := make(chan os.Signal, 1)
+	signal.Notify(interrupts, os.Interrupt)
+	cancel := func() {}
+
+	input := newLineReader(os.Stdin)
+	completer := newRemoteCompleter(client)
 
 	fmt.Println("SFTP Client v1.0")
 	fmt.Println("Type 'help' for available commands")
 
 	for {
+		cancel()
+		prompt := "sftp> "
 		if client.IsConnected() {
 			wd, _ := client.GetWorkingDirectory()
-			fmt.Printf("sftp:%s> ", wd)
-		} else {
-			fmt.Print("sftp> ")
+			prompt = fmt.Sprintf("sftp:%s> ", wd)
 		}
 
-		if !scanner.Scan() {
+		line, ok := input.readLine(prompt, completer.completeLine)
+		if !ok {
 			break
 		}
 
-		input := strings.TrimSpace(scanner.Text())
-		if input == "" {
+		line = strings.TrimSpace(line)
+		if line == "" {
 			continue
 		}
 
-		parts := strings.Fields(input)
+		parts := strings.Fields(line)
 		command := strings.ToLower(parts[0])
+		var ctx context.Context
+		ctx, cancel = commandContext(interrupts)
 
 		switch command {
 		case "help":
@@ -323,7 +1260,7 @@ func main() {
 				}
 			}
 
-			err := client.Connect(host, username, password, port)
+			err := client.ConnectContext(ctx, host, username, password, port)
 			if err != nil {
 				fmt.Printf("Connection failed: %v\n", err)
 			} else {
@@ -350,7 +1287,7 @@ func main() {
 				}
 			}
 
-			err := client.ConnectWithKey(host, username, keyPath, port)
+			err := client.ConnectWithKeyContext(ctx, host, username, keyPath, port)
 			if err != nil {
 				fmt.Printf("Connection failed: %v\n", err)
 			} else {
@@ -372,11 +1309,16 @@ func main() {
 			}
 
 			path := "."
-			if len(parts) > 1 {
-				path = parts[1]
+			showAll := false
+			for _, arg := range parts[1:] {
+				if arg == "-a" {
+					showAll = true
+				} else {
+					path = arg
+				}
 			}
 
-			err := client.ListDirectory(path)
+			err := client.ListDirectoryContext(ctx, path, showAll)
 			if err != nil {
 				fmt.Printf("List directory failed: %v\n", err)
 			}
@@ -394,6 +1336,29 @@ func main() {
 				fmt.Println(wd)
 			}
 
+		case "df":
+			remotePath := "."
+			if len(parts) > 1 {
+				remotePath = parts[1]
+			}
+
+			if err := client.DiskFreeContext(ctx, remotePath); err != nil {
+				fmt.Printf("Disk usage failed: %v\n", err)
+			}
+
+		case "du":
+			remotePath := "."
+			if len(parts) > 1 {
+				remotePath = parts[1]
+			}
+
+			dirs, files, bytes, err := client.ScanTreeContext(ctx, remotePath)
+			if err != nil {
+				fmt.Printf("Folder size failed: %v\n", err)
+				continue
+			}
+			fmt.Printf("%s\t%s (%d file(s), %d folder(s))\n", formatBytes(uint64(bytes)), remotePath, files, dirs)
+
 		case "upload":
 			if len(parts) < 3 {
 				fmt.Println("Usage: upload <local_file> <remote_file>")
@@ -403,7 +1368,23 @@ func main() {
 			localFile := parts[1]
 			remoteFile := parts[2]
 
-			err := client.UploadFile(localFile, remoteFile)
+			// Warn before filling up the remote disk
+			if info, err := os.Stat(localFile); err == nil {
+				if free, _, err := client.FreeSpaceContext(ctx, path.Dir(remoteFile)); err == nil && uint64(info.Size()) > free {
+					fmt.Printf("%s needs %s but only %s is free on the server. Upload anyway? [y/N] ",
+						localFile, formatBytes(uint64(info.Size())), formatBytes(free))
+					answer := ""
+					if reply, ok := input.readLine("", nil); ok {
+						answer = strings.TrimSpace(reply)
+					}
+					if !strings.EqualFold(answer, "y") {
+						fmt.Println("Cancelled")
+						continue
+					}
+				}
+			}
+
+			err := client.UploadFileContext(ctx, localFile, remoteFile)
 			if err != nil {
 				fmt.Printf("Upload failed: %v\n", err)
 			}
@@ -417,7 +1398,7 @@ func main() {
 			remoteFile := parts[1]
 			localFile := parts[2]
 
-			err := client.DownloadFile(remoteFile, localFile)
+			err := client.DownloadFileContext(ctx, remoteFile, localFile)
 			if err != nil {
 				fmt.Printf("Download failed: %v\n", err)
 			}
@@ -447,15 +1428,146 @@ func main() {
 			}
 
 		case "rmdir":
-			if len(parts) < 2 {
-				fmt.Println("Usage: rmdir <remote_directory>")
+			args := parts[1:]
+			recursive := false
+			if len(args) > 0 && args[0] == "-r" {
+				recursive = true
+				args = args[1:]
+			}
+			if len(args) < 1 {
+				fmt.Println("Usage: rmdir [-r] <remote_directory>")
 				continue
 			}
 
-			remoteDir := parts[1]
-			err := client.RemoveDirectory(remoteDir)
+			remoteDir := args[0]
+			if !recursive {
+				err := client.RemoveDirectory(remoteDir)
+				if err != nil {
+					fmt.Printf("Remove directory failed: %v\n", err)
+				}
+				continue
+			}
+
+			dirs, files, bytes, err := client.ScanTreeContext(ctx, remoteDir)
 			if err != nil {
 				fmt.Printf("Remove directory failed: %v\n", err)
+				continue
+			}
+			fmt.Printf("This removes %d folder(s) and %d file(s), %d bytes.\n", dirs, files, bytes)
+			phrase := "y"
+			if dirs+files > confirmDeleteItems || bytes > confirmDeleteBytes {
+				phrase = remoteDir
+				fmt.Printf("Type '%s' to confirm: ", phrase)
+			} else {
+				fmt.Print("Continue? [y/N] ")
+			}
+			answer := ""
+			if reply, ok := input.readLine("", nil); ok {
+				answer = strings.TrimSpace(reply)
+			}
+			if answer != phrase && !(phrase == "y" && answer == "Y") {
+				fmt.Println("Cancelled")
+				continue
+			}
+
+			if err := client.RemoveTreeContext(ctx, remoteDir); err != nil {
+				fmt.Printf("Remove directory failed: %v\n", err)
+			}
+
+		case "rename", "mv":
+			args := parts[1:]
+			overwrite := false
+			if len(args) > 0 && args[0] == "-f" {
+				overwrite = true
+				args = args[1:]
+			}
+			if len(args) < 2 {
+				fmt.Printf("Usage: %s [-f] <old_path> <new_path>\n", command)
+				continue
+			}
+
+			err := client.RenameFileContext(ctx, args[0], args[1], overwrite)
+			if err != nil {
+				fmt.Printf("Rename failed: %v\n", err)
+			}
+
+		case "ln":
+			if len(parts) < 4 || parts[1] != "-s" {
+				fmt.Println("Usage: ln -s <target> <link_path>")
+				continue
+			}
+
+			err := client.CreateSymlinkContext(ctx, parts[2], parts[3])
+			if err != nil {
+				fmt.Printf("Create symlink failed: %v\n", err)
+			}
+
+		case "readlink":
+			if len(parts) < 2 {
+				fmt.Println("Usage: readlink <link_path>")
+				continue
+			}
+
+			target, err := client.ReadLink(parts[1])
+			if err != nil {
+				fmt.Printf("Readlink failed: %v\n", err)
+			} else {
+				fmt.Println(target)
+			}
+
+		case "diff":
+			args := parts[1:]
+			local := len(args) > 0 && args[0] == "-l"
+			if local {
+				args = args[1:]
+			}
+			if len(args) < 2 {
+				fmt.Println("Usage: diff [-l] <file_a> <remote_file_b>")
+				continue
+			}
+
+			if err := client.DiffContext(ctx, args[0], args[1], local); err != nil {
+				fmt.Printf("Diff failed: %v\n", err)
+			}
+
+		case "chmod":
+			args, recursive := recursiveFlag(parts[1:])
+			if len(args) < 2 {
+				fmt.Println("Usage: chmod [-R] <octal_mode> <remote_path>")
+				continue
+			}
+
+			mode, err := parseMode(args[0])
+			if err != nil {
+				fmt.Printf("Chmod failed: %v\n", err)
+				continue
+			}
+			if err := client.ChangeModeContext(ctx, args[1], mode, recursive); err != nil {
+				fmt.Printf("Chmod failed: %v\n", err)
+			}
+
+		case "chown", "chgrp":
+			args, recursive := recursiveFlag(parts[1:])
+			if len(args) < 2 {
+				if command == "chown" {
+					fmt.Println("Usage: chown [-R] <uid>[:<gid>] <remote_path>")
+				} else {
+					fmt.Println("Usage: chgrp [-R] <gid> <remote_path>")
+				}
+				continue
+			}
+
+			owner := args[0]
+			if command == "chgrp" {
+				owner = ":" + owner
+			}
+			uid, gid, err := parseOwner(owner)
+			if err != nil {
+				fmt.Printf("Change owner failed: %v\n", err)
+				continue
+			}
+			if err := client.ChangeOwnerContext(ctx, args[1], uid, gid, recursive); err != nil {
+				fmt.Printf("Change owner failed: %v\n", err)
 			}
 
 		case "quit", "exit":
@@ -467,8 +1579,4 @@ func main() {
 			fmt.Println("Type 'help' for available commands")
 		}
 	}
-
-	if err := scanner.Err(); err != nil {
-		log.Fatalf("Error reading input: %v", err)
-	}
 }
@@ -0,0 +1,310 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/pmezard/go-difflib/difflib"
+)
+
+// compareLimit is the largest file the Compare action loads
+const compareLimit = 5 << 20
+
+// unifiedDiff returns the unified diff turning a into b, empty when they are equal
+func unifiedDiff(a, b, aName, bName string) string {
+	diff, _ := difflib.GetUnifiedDiffString(difflib.UnifiedDiff{
+		A:        difflib.SplitLines(a),
+		B:        difflib.SplitLines(b),
+		FromFile: aName,
+		ToFile:   bName,
+		Context:  3,
+	})
+	return diff
+}
+
+// diffRow is one line of a side-by-side diff. op is 'e' for equal lines,
+// 'd' for a line only on the left, 'i' for one only on the right and 'r'
+// for a changed line.
+type diffRow struct {
+	left, right string
+	op          byte
+}
+
+// sideBySide aligns the lines of a and b for a two column diff
+func sideBySide(a, b string) []diffRow {
+	aLines := splitDiffLines(a)
+	bLines := splitDiffLines(b)
+
+	var rows []diffRow
+	for _, op := range difflib.NewMatcher(aLines, bLines).GetOpCodes() {
+		left, right := aLines[op.I1:op.I2], bLines[op.J1:op.J2]
+		for i := 0; i < len(left) || i < len(right); i++ {
+			row := diffRow{op: op.Tag}
+			if i < len(left) {
+				row.left = left[i]
+			}
+			if i < len(right) {
+				row.right = right[i]
+			}
+			// The unmatched rest of a longer replacement is a pure insert or delete
+			if op.Tag == 'r' && i >= len(left) {
+				row.op = 'i'
+			} else if op.Tag == 'r' && i >= len(right) {
+				row.op = 'd'
+			}
+			rows = append(rows, row)
+		}
+	}
+	return rows
+}
+
+func splitDiffLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// compareFile is one side of a comparison
+type compareFile struct {
+	label string
+	read  func(ctx context.Context) ([]byte, error)
+	write func(ctx context.Context, data []byte) error
+}
+
+func (s *session) localCompareFile(name string) compareFile {
+	localPath := filepath.Join(s.currentLocal, name)
+	return compareFile{
+		label: localPath,
+		read: func(_ context.Context) ([]byte, error) {
+			f, err := os.Open(localPath)
+			if err != nil {
+				return nil, err
+			}
+			defer f.Close()
+			return readCompareFile(f)
+		},
+		write: func(_ context.Context, data []byte) error {
+			// WriteFile keeps the mode of an existing file
+			return os.WriteFile(localPath, data, 0644)
+		},
+	}
+}
+
+func (s *session) remoteCompareFile(name string) compareFile {
+	remotePath := path.Join(s.currentRemote, name)
+	return compareFile{
+		label: remotePath,
+		read: func(ctx context.Context) ([]byte, error) {
+			f, err := s.client.sftpClient.Open(remotePath)
+			if err != nil {
+				return nil, err
+			}
+			defer f.Close()
+			defer closeOnCancel(ctx, f)()
+			data, err := readCompareFile(f)
+			return data, cancelled(ctx, err)
+		},
+		write: func(ctx context.Context, data []byte) error {
+			defer s.remoteChanged(remotePath)
+			f, err := s.client.sftpClient.Create(remotePath)
+			if err != nil {
+				return err
+			}
+			stop := closeOnCancel(ctx, f)
+			_, err = f.Write(data)
+			stop()
+			if closeErr := f.Close(); err == nil {
+				err = closeErr
+			}
+			return cancelled(ctx, err)
+		},
+	}
+}
+
+// readCompareFile reads a text file of at most compareLimit bytes
+func readCompareFile(r io.Reader) ([]byte, error) {
+	data, err := io.ReadAll(io.LimitReader(r, compareLimit+1))
+	if err != nil {
+		return nil, err
+	}
+	if len(data) > compareLimit {
+		return nil, fmt.Errorf("file is larger than %s", humanSize(compareLimit))
+	}
+	if !looksLikeText(data) {
+		return nil, fmt.Errorf("not a text file")
+	}
+	return data, nil
+}
+
+// onCompare compares the selected local file with the selected remote
+// file, or the two files selected in the active pane
+func (s *session) onCompare() {
+	local := s.localPane.selectedEntries()
+	var remote []fileEntry
+	if s.client.IsConnected() {
+		remote = s.remotePane.selectedEntries()
+	}
+
+	var left, right compareFile
+	switch {
+	case s.activePane == s.remotePane && len(remote) == 2:
+		left, right = s.remoteCompareFile(remote[0].Name), s.remoteCompareFile(remote[1].Name)
+	case s.activePane == s.localPane && len(local) == 2:
+		left, right = s.localCompareFile(local[0].Name), s.localCompareFile(local[1].Name)
+	case len(local) == 1 && len(remote) == 1:
+		left, right = s.localCompareFile(local[0].Name), s.remoteCompareFile(remote[0].Name)
+	default:
+		s.showError("Select one local and one remote file, or two files in the same pane, to compare")
+		return
+	}
+
+	s.showProgress(fmt.Sprintf("Comparing %s and %s...", left.label, right.label))
+	ctx, done := s.startOperation()
+	go func() {
+		defer done()
+		leftData, err := left.read(ctx)
+		if ctx.Err() != nil {
+			s.hideProgress()
+			s.logMessage("Compare cancelled")
+			return
+		}
+		if err != nil {
+			s.hideProgress()
+			s.showError(fmt.Sprintf("Cannot compare %s: %v", left.label, err))
+			return
+		}
+		rightData, err := right.read(ctx)
+		s.hideProgress()
+		if ctx.Err() != nil {
+			s.logMessage("Compare cancelled")
+			return
+		}
+		if err != nil {
+			s.showError(fmt.Sprintf("Cannot compare %s: %v", right.label, err))
+			return
+		}
+		s.do(func() { s.showCompare(left, right, string(leftData), string(rightData)) })
+	}()
+}
+
+// showCompare shows the differences between two files with buttons to
+// copy either side over the other
+func (s *session) showCompare(left, right compareFile, leftText, rightText string) {
+	if leftText == rightText {
+		dialog.ShowInformation("Compare", fmt.Sprintf("%s and %s are identical.", left.label, right.label), s.window)
+		return
+	}
+
+	unified := container.NewScroll(unifiedDiffView(unifiedDiff(leftText, rightText, left.label, right.label)))
+	sideLeft, sideRight := sideBySideViews(sideBySide(leftText, rightText))
+	sideBySideView := container.NewScroll(container.NewGridWithColumns(2,
+		container.NewBorder(widget.NewLabelWithStyle(left.label, fyne.TextAlignLeading, fyne.TextStyle{Bold: true}), nil, nil, nil, sideLeft),
+		container.NewBorder(widget.NewLabelWithStyle(right.label, fyne.TextAlignLeading, fyne.TextStyle{Bold: true}), nil, nil, nil, sideRight),
+	))
+
+	views := container.NewStack(sideBySideView)
+	modeSelect := widget.NewRadioGroup([]string{"Side by side", "Unified"}, func(mode string) {
+		if mode == "Unified" {
+			views.Objects = []fyne.CanvasObject{unified}
+		} else {
+			views.Objects = []fyne.CanvasObject{sideBySideView}
+		}
+		views.Refresh()
+	})
+	modeSelect.Horizontal = true
+	modeSelect.SetSelected("Side by side")
+
+	var d dialog.Dialog
+	apply := func(from, to compareFile, text string) func() {
+		return func() {
+			dialog.ShowConfirm("Apply Changes", fmt.Sprintf("Overwrite %s with %s?", to.label, from.label), func(confirmed bool) {
+				if !confirmed {
+					return
+				}
+				s.showProgress(fmt.Sprintf("Writing %s...", to.label))
+				ctx, done := s.startOperation()
+				go func() {
+					defer done()
+					err := to.write(ctx, []byte(text))
+					s.hideProgress()
+					switch {
+					case ctx.Err() != nil:
+						s.logMessage(fmt.Sprintf("Writing %s cancelled", to.label))
+					case err != nil:
+						s.showError(fmt.Sprintf("Failed to write %s: %v", to.label, err))
+					default:
+						s.logMessage(fmt.Sprintf("Copied %s to %s", from.label, to.label))
+						s.do(func() {
+							d.Hide()
+							s.onRefresh()
+						})
+					}
+				}()
+			}, s.window)
+		}
+	}
+	toRight := widget.NewButtonWithIcon("Apply Left → Right", theme.NavigateNextIcon(), apply(left, right, leftText))
+	toLeft := widget.NewButtonWithIcon("Apply Right → Left", theme.NavigateBackIcon(), apply(right, left, rightText))
+
+	content := container.NewBorder(
+		container.NewHBox(modeSelect),
+		container.NewHBox(toLeft, toRight),
+		nil, nil,
+		views,
+	)
+	d = dialog.NewCustom("Compare Files", "Close", content, s.window)
+	d.Resize(fyne.NewSize(1000, 650))
+	d.Show()
+}
+
+// unifiedDiffView colors a unified diff by line type
+func unifiedDiffView(diff string) fyne.CanvasObject {
+	var segments []widget.RichTextSegment
+	for _, line := range splitDiffLines(diff) {
+		style := widget.RichTextStyleCodeBlock
+		switch {
+		case strings.HasPrefix(line, "+++"), strings.HasPrefix(line, "---"):
+			style.TextStyle.Bold = true
+		case strings.HasPrefix(line, "@@"):
+			style.ColorName = theme.ColorNamePrimary
+		case strings.HasPrefix(line, "+"):
+			style.ColorName = theme.ColorNameSuccess
+		case strings.HasPrefix(line, "-"):
+			style.ColorName = theme.ColorNameError
+		}
+		segments = append(segments, &widget.TextSegment{Text: line, Style: style})
+	}
+	return widget.NewRichText(segments...)
+}
+
+// sideBySideViews renders the two columns of a side-by-side diff
+func sideBySideViews(rows []diffRow) (fyne.CanvasObject, fyne.CanvasObject) {
+	var left, right []widget.RichTextSegment
+	for _, row := range rows {
+		leftStyle, rightStyle := widget.RichTextStyleCodeBlock, widget.RichTextStyleCodeBlock
+		switch row.op {
+		case 'd':
+			leftStyle.ColorName = theme.ColorNameError
+		case 'i':
+			rightStyle.ColorName = theme.ColorNameSuccess
+		case 'r':
+			leftStyle.ColorName = theme.ColorNameError
+			rightStyle.ColorName = theme.ColorNameSuccess
+		}
+		// Blank placeholder lines keep both columns aligned
+		left = append(left, &widget.TextSegment{Text: row.left + " ", Style: leftStyle})
+		right = append(right, &widget.TextSegment{Text: row.right + " ", Style: rightStyle})
+	}
+	return widget.NewRichText(left...), widget.NewRichText(right...)
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestUnifiedDiff(t *testing.T) {
+	if diff := unifiedDiff("a\nb\n", "a\nb\n", "left", "right"); diff != "" {
+		t.Errorf("Expected no diff for equal text, got %q", diff)
+	}
+
+	diff := unifiedDiff("a\nb\nc\n", "a\nB\nc\n", "left", "right")
+	for _, want := range []string{"--- left", "+++ right", "-b\n", "+B\n", " a\n"} {
+		if !strings.Contains(diff, want) {
+			t.Errorf("Diff missing %q:\n%s", want, diff)
+		}
+	}
+}
+
+func TestSideBySide(t *testing.T) {
+	rows := sideBySide("a\nb\nc\nd\n", "a\nB\nX\nc\n")
+	want := []diffRow{
+		{"a", "a", 'e'},
+		{"b", "B", 'r'},
+		{"", "X", 'i'},
+		{"c", "c", 'e'},
+		{"d", "", 'd'},
+	}
+	if len(rows) != len(want) {
+		t.Fatalf("Expected %d rows, got %v", len(want), rows)
+	}
+	for i := range want {
+		if rows[i] != want[i] {
+			t.Errorf("Row %d = %+v, want %+v", i, rows[i], want[i])
+		}
+	}
+}
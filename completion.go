@@ -0,0 +1,310 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Completion limits
+const (
+	completionCacheTTL = 10 * time.Second
+	completionMax      = 50
+)
+
+// ListDirs returns the names of the folders in dir, including symlinks
+// to folders
+func (c *SFTPGUIClient) ListDirs(dir string) ([]string, error) {
+	return c.ListDirsContext(context.Background(), dir)
+}
+
+// ListDirsContext is ListDirs, giving up once ctx is done
+func (c *SFTPGUIClient) ListDirsContext(ctx context.Context, dir string) ([]string, error) {
+	if !c.IsConnected() {
+		return nil, nil
+	}
+
+	infos, err := c.sftpClient.ReadDirContext(ctx, dir)
+	if err != nil {
+		return nil, cancelled(ctx, err)
+	}
+	var names []string
+	for _, info := range infos {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+		isDir := info.IsDir()
+		if info.Mode()&os.ModeSymlink != 0 {
+			if target, err := c.sftpClient.Stat(path.Join(dir, info.Name())); err == nil {
+				isDir = target.IsDir()
+			}
+		}
+		if isDir {
+			names = append(names, info.Name())
+		}
+	}
+	sort.Strings(names)
+	return names, nil
+}
+
+// dirCompleter completes remote folder paths. Listings are cached for
+// completionCacheTTL so typing doesn't list the same folder repeatedly.
+type dirCompleter struct {
+	list func(ctx context.Context, dir string) ([]string, error)
+
+	mu    sync.Mutex
+	cache map[string]dirListing
+}
+
+type dirListing struct {
+	names []string
+	at    time.Time
+}
+
+func newDirCompleter(list func(ctx context.Context, dir string) ([]string, error)) *dirCompleter {
+	return &dirCompleter{list: list, cache: make(map[string]dirListing)}
+}
+
+// names returns the cached or freshly listed folder names of dir
+func (c *dirCompleter) names(ctx context.Context, dir string) []string {
+	c.mu.Lock()
+	listing, ok := c.cache[dir]
+	c.mu.Unlock()
+	if ok && time.Since(listing.at) < completionCacheTTL {
+		return listing.names
+	}
+
+	names, err := c.list(ctx, dir)
+	if err != nil {
+		return nil
+	}
+	c.mu.Lock()
+	c.cache[dir] = dirListing{names: names, at: time.Now()}
+	c.mu.Unlock()
+	return names
+}
+
+// forget drops the cached listing of dir, for example after it changed
+func (c *dirCompleter) forget(dir string) {
+	c.mu.Lock()
+	delete(c.cache, dir)
+	c.mu.Unlock()
+}
+
+// complete returns the folder paths starting with text, each ending in
+// a slash. Relative paths are completed from cwd and hidden folders are
+// only offered once a dot has been typed. Listing a folder stops once
+// ctx is done.
+func (c *dirCompleter) complete(ctx context.Context, text, cwd string) []string {
+	dirPart, prefix := "", text
+	if i := strings.LastIndex(text, "/"); i >= 0 {
+		dirPart, prefix = text[:i+1], text[i+1:]
+	}
+	dir := path.Clean(dirPart)
+	if dirPart == "" {
+		dir = cwd
+	} else if !path.IsAbs(dir) {
+		dir = path.Join(cwd, dir)
+	}
+
+	var options []string
+	for _, name := range c.names(ctx, dir) {
+		if !strings.HasPrefix(name, prefix) || (strings.HasPrefix(name, ".") && !strings.HasPrefix(prefix, ".")) {
+			continue
+		}
+		options = append(options, dirPart+name+"/")
+		if len(options) == completionMax {
+			break
+		}
+	}
+	return options
+}
+
+// completionEntry is an entry that shows completions of the typed text
+// in a popup below it. Tab accepts the highlighted completion.
+type completionEntry struct {
+	widget.Entry
+
+	// complete returns the lookup of the completions of text, which runs
+	// on a goroutine. Its results are shown through dispatch.
+	complete func(text string) func(ctx context.Context) []string
+	dispatch func(update func())
+	// cancel stops the lookup that is running, if any
+	cancel context.CancelFunc
+
+	options   []string
+	highlight int
+	list      *completionList
+	popup     *widget.PopUp
+}
+
+func newCompletionEntry(complete func(text string) func(ctx context.Context) []string, dispatch func(update func())) *completionEntry {
+	e := &completionEntry{complete: complete, dispatch: dispatch}
+	e.ExtendBaseWidget(e)
+	e.list = newCompletionList(e)
+	return e
+}
+
+// TypedRune updates the completions after the user typed a character
+func (e *completionEntry) TypedRune(r rune) {
+	e.Entry.TypedRune(r)
+	e.updateCompletions()
+}
+
+// TypedKey updates the completions after the user deleted text
+func (e *completionEntry) TypedKey(key *fyne.KeyEvent) {
+	e.Entry.TypedKey(key)
+	switch key.Name {
+	case fyne.KeyBackspace, fyne.KeyDelete:
+		e.updateCompletions()
+	case fyne.KeyReturn, fyne.KeyEnter, fyne.KeyEscape:
+		e.hideCompletions()
+	}
+}
+
+// updateCompletions looks up the completions of the current text in the
+// background and shows them unless the text changed in the meantime. A
+// lookup still running for earlier text is cancelled.
+func (e *completionEntry) updateCompletions() {
+	text := e.Text
+	if text == "" || e.complete == nil {
+		e.hideCompletions()
+		return
+	}
+	e.stopLookup()
+	ctx, cancel := context.WithCancel(context.Background())
+	e.cancel = cancel
+	lookup := e.complete(text)
+	go func() {
+		options := lookup(ctx)
+		e.dispatch(func() {
+			if ctx.Err() != nil {
+				return
+			}
+			e.stopLookup()
+			if e.Text == text {
+				e.showCompletions(options)
+			}
+		})
+	}()
+}
+
+func (e *completionEntry) showCompletions(options []string) {
+	if len(options) == 0 || (len(options) == 1 && options[0] == e.Text) {
+		e.hideCompletions()
+		return
+	}
+	c := fyne.CurrentApp().Driver().CanvasForObject(e)
+	if c == nil {
+		return
+	}
+
+	e.options, e.highlight = options, 0
+	if e.popup == nil {
+		e.popup = widget.NewPopUp(e.list, c)
+	}
+	height := fyne.Min(float32(len(options))*e.list.rowHeight(), 200)
+	e.popup.Resize(fyne.NewSize(e.Size().Width, height))
+	pos := fyne.CurrentApp().Driver().AbsolutePositionForObject(e)
+	e.popup.ShowAtPosition(pos.Add(fyne.NewPos(0, e.Size().Height)))
+	e.list.Refresh()
+	e.list.ScrollToTop()
+
+	// The popup takes the keyboard, the list hands typing back to the entry
+	c.Focus(e.list)
+}
+
+func (e *completionEntry) stopLookup() {
+	if e.cancel != nil {
+		e.cancel()
+		e.cancel = nil
+	}
+}
+
+func (e *completionEntry) hideCompletions() {
+	e.stopLookup()
+	if e.popup != nil && e.popup.Visible() {
+		e.popup.Hide()
+		if c := fyne.CurrentApp().Driver().CanvasForObject(e); c != nil {
+			c.Focus(e)
+		}
+	}
+}
+
+// accept replaces the text with a completion and looks up the next level
+func (e *completionEntry) accept(option string) {
+	e.hideCompletions()
+	e.SetText(option)
+	e.CursorColumn = len([]rune(option))
+	e.Refresh()
+	e.updateCompletions()
+}
+
+// completionList shows the completions of an entry and, since the popup
+// holding it has the keyboard focus, forwards other typing to the entry
+type completionList struct {
+	widget.List
+	entry *completionEntry
+}
+
+func newCompletionList(entry *completionEntry) *completionList {
+	l := &completionList{entry: entry}
+	l.Length = func() int { return len(entry.options) }
+	l.CreateItem = func() fyne.CanvasObject { return widget.NewLabel("") }
+	l.UpdateItem = func(id widget.ListItemID, obj fyne.CanvasObject) {
+		label := obj.(*widget.Label)
+		label.TextStyle.Bold = id == entry.highlight
+		label.SetText(entry.options[id])
+	}
+	l.OnSelected = func(id widget.ListItemID) {
+		l.Unselect(id)
+		if id < len(entry.options) {
+			entry.accept(entry.options[id])
+		}
+	}
+	l.ExtendBaseWidget(l)
+	return l
+}
+
+func (l *completionList) rowHeight() float32 {
+	return widget.NewLabel("Ag").MinSize().Height + 4
+}
+
+// AcceptsTab lets Tab reach TypedKey instead of moving the focus
+func (l *completionList) AcceptsTab() bool {
+	return true
+}
+
+func (l *completionList) TypedRune(r rune) {
+	l.entry.TypedRune(r)
+}
+
+func (l *completionList) TypedKey(key *fyne.KeyEvent) {
+	e := l.entry
+	switch key.Name {
+	case fyne.KeyDown, fyne.KeyUp:
+		if key.Name == fyne.KeyDown && e.highlight < len(e.options)-1 {
+			e.highlight++
+		} else if key.Name == fyne.KeyUp && e.highlight > 0 {
+			e.highlight--
+		}
+		l.ScrollTo(e.highlight)
+		l.Refresh()
+	case fyne.KeyTab:
+		if e.highlight < len(e.options) {
+			e.accept(e.options[e.highlight])
+		}
+	case fyne.KeyEscape:
+		e.hideCompletions()
+	default:
+		e.hideCompletions()
+		e.TypedKey(key)
+	}
+}
@@ -0,0 +1,65 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestDirCompleter(t *testing.T) {
+	listings := map[string][]string{
+		"/":         {"etc", "srv", "var"},
+		"/srv":      {".cache", "app", "archive", "www"},
+		"/home/bob": {"src"},
+	}
+	calls := 0
+	c := newDirCompleter(func(ctx context.Context, dir string) ([]string, error) {
+		calls++
+		return listings[dir], nil
+	})
+
+	tests := []struct {
+		text string
+		want []string
+	}{
+		{"/srv/a", []string{"/srv/app/", "/srv/archive/"}},
+		{"/srv/", []string{"/srv/app/", "/srv/archive/", "/srv/www/"}},
+		{"/srv/.", []string{"/srv/.cache/"}},
+		{"/s", []string{"/srv/"}},
+		{"s", []string{"src/"}},
+		{"../../s", []string{"../../srv/"}},
+		{"/srv/x", nil},
+	}
+	for _, tt := range tests {
+		if got := c.complete(context.Background(), tt.text, "/home/bob"); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("complete(%q) = %q, want %q", tt.text, got, tt.want)
+		}
+	}
+	if calls != 3 {
+		t.Errorf("Expected each folder to be listed once, got %d listings", calls)
+	}
+
+	c.forget("/srv")
+	c.complete(context.Background(), "/srv/", "/")
+	if calls != 4 {
+		t.Error("A forgotten folder should be listed again")
+	}
+}
+
+func TestSFTPGUIClient_ListDirs(t *testing.T) {
+	client, root := newTestClient(t)
+	writeTestFiles(t, root, map[string]string{"b/x": "", "a/x": "", "file.txt": ""})
+	if err := os.Symlink(filepath.Join(root, "a"), filepath.Join(root, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	names, err := client.ListDirs(root)
+	if err != nil {
+		t.Fatalf("ListDirs failed: %v", err)
+	}
+	if want := []string{"a", "b", "link"}; !reflect.DeepEqual(names, want) {
+		t.Errorf("ListDirs = %q, want %q", names, want)
+	}
+}
@@ -50,7 +50,7 @@ func ExampleProgrammaticUsage() {
 	// Example operations
 
 	// List current directory
-	err := client.ListDirectory(".")
+	err := client.ListDirectory(".", false)
 	if err != nil {
 		log.Printf("Failed to list directory: %v", err)
 	}
@@ -111,7 +111,7 @@ func ExampleSSHKeyUsage() {
 	fmt.Println("Connected using SSH key authentication")
 
 	// Perform operations...
-	err = client.ListDirectory("/home/user")
+	err = client.ListDirectory("/home/user", false)
 	if err != nil {
 		log.Printf("Failed to list directory: %v", err)
 	}
@@ -0,0 +1,251 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"net"
+	"os"
+	"time"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+// closeOnCancel closes c as soon as ctx is done, so that a read or write
+// blocked on a hung server returns. Calling stop ends the watch; it
+// reports false when c was already closed.
+func closeOnCancel(ctx context.Context, c io.Closer) (stop func() bool) {
+	return context.AfterFunc(ctx, func() { c.Close() })
+}
+
+// cancelled returns the error of ctx in place of err once ctx is done,
+// since closing a file or connection surfaces as an unrelated error
+func cancelled(ctx context.Context, err error) error {
+	if err != nil && ctx.Err() != nil {
+		return ctx.Err()
+	}
+	return err
+}
+
+// cleanupTimeout bounds how long removing the partial file of a
+// cancelled transfer may take
+const cleanupTimeout = 10 * time.Second
+
+// openContext runs open, giving up once ctx is done. The SFTP request
+// cannot be interrupted, so a file that opens after that is closed in the
+// background, once abandoned ran if it is set.
+func openContext(ctx context.Context, open func() (*sftp.File, error), abandoned func()) (*sftp.File, error) {
+	if ctx.Err() != nil {
+		return nil, ctx.Err()
+	}
+	type opened struct {
+		file *sftp.File
+		err  error
+	}
+	result := make(chan opened, 1)
+	go func() {
+		file, err := open()
+		result <- opened{file, err}
+	}()
+
+	select {
+	case r := <-result:
+		return r.file, r.err
+	case <-ctx.Done():
+		go func() {
+			if r := <-result; r.err == nil {
+				if abandoned != nil {
+					abandoned()
+				}
+				r.file.Close()
+			}
+		}()
+		return nil, ctx.Err()
+	}
+}
+
+// removePartial removes the partial file of a cancelled transfer in the
+// background. The connection may be hung, which is often why the transfer
+// was cancelled, so the file is removed over an SFTP session of its own
+// that is closed after cleanupTimeout.
+func (c *SFTPGUIClient) removePartial(remotePath string) {
+	c.mu.Lock()
+	sftpClient, openChannel := c.sftpClient, c.openChannel
+	c.mu.Unlock()
+	if openChannel == nil {
+		go sftpClient.Remove(remotePath)
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), cleanupTimeout)
+		defer cancel()
+		ch, err := openChannel()
+		if err != nil {
+			return
+		}
+		defer closeOnCancel(ctx, ch)()
+		cleanup, err := sftp.NewClientPipe(ch, ch)
+		if err != nil {
+			ch.Close()
+			return
+		}
+		defer cleanup.Close()
+		cleanup.Remove(remotePath)
+	}()
+}
+
+// ConnectContext connects with password authentication like Connect. The
+// connection attempt is abandoned once ctx is done.
+func (c *SFTPGUIClient) ConnectContext(ctx context.Context, host, username, password string, port int) error {
+	config := &ssh.ClientConfig{
+		User: username,
+		Auth: []ssh.AuthMethod{
+			ssh.Password(password),
+		},
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		Timeout:         30 * time.Second,
+	}
+	return c.dial(ctx, host, port, config)
+}
+
+// ConnectWithKeyContext connects with key authentication like
+// ConnectWithKey. The connection attempt is abandoned once ctx is done.
+func (c *SFTPGUIClient) ConnectWithKeyContext(ctx context.Context, host, username, keyPath string, port int) error {
+	key, err := os.ReadFile(keyPath)
+	if err != nil {
+		return fmt.Errorf("unable to read private key: %v", err)
+	}
+
+	signer, err := ssh.ParsePrivateKey(key)
+	if err != nil {
+		return fmt.Errorf("unable to parse private key: %v", err)
+	}
+
+	config := &ssh.ClientConfig{
+		User: username,
+		Auth: []ssh.AuthMethod{
+			ssh.PublicKeys(signer),
+		},
+		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
+		Timeout:         30 * time.Second,
+	}
+	return c.dial(ctx, host, port, config)
+}
+
+// dial opens the SSH connection and the SFTP session on top of it. The
+// handshake is aborted by closing the network connection when ctx is done.
+func (c *SFTPGUIClient) dial(ctx context.Context, host string, port int, config *ssh.ClientConfig) error {
+	addr := fmt.Sprintf("%s:%d", host, port)
+	conn, err := (&net.Dialer{Timeout: config.Timeout}).DialContext(ctx, "tcp", addr)
+	if err != nil {
+		return fmt.Errorf("failed to connect to SSH server: %v", cancelled(ctx, err))
+	}
+
+	stop := closeOnCancel(ctx, conn)
+	sshConn, chans, reqs, err := ssh.NewClientConn(conn, addr, config)
+	if err != nil {
+		stop()
+		conn.Close()
+		return fmt.Errorf("failed to connect to SSH server: %v", cancelled(ctx, err))
+	}
+	sshClient := ssh.NewClient(sshConn, chans, reqs)
+
+	sftpClient, err := sftp.NewClient(sshClient)
+	if !stop() {
+		err = ctx.Err()
+	}
+	if err != nil {
+		sshClient.Close()
+		return fmt.Errorf("failed to create SFTP client: %v", err)
+	}
+
+	c.mu.Lock()
+	c.sshClient = sshClient
+	c.sftpClient = sftpClient
+	c.openChannel = func() (io.ReadWriteCloser, error) { return openSFTPChannel(sshClient) }
+	c.connected = true
+	c.mu.Unlock()
+	return nil
+}
+
+// GetFilesContext returns the entries of dir like GetFiles, giving up
+// once ctx is done
+func (c *SFTPGUIClient) GetFilesContext(ctx context.Context, dir string) ([]fileEntry, error) {
+	var entries []fileEntry
+	err := c.ListFiles(ctx, dir, func(batch []fileEntry) {
+		entries = append(entries, batch...)
+	})
+	if err != nil {
+		return nil, err
+	}
+	return entries, nil
+}
+
+// UploadFileContext copies a local file to remotePath. When ctx is done
+// the remote file is closed, which ends a write blocked on the server,
+// and the partial file is removed in the background.
+func (c *SFTPGUIClient) UploadFileContext(ctx context.Context, localPath, remotePath string) error {
+	if !c.IsConnected() {
+		return fmt.Errorf("not connected")
+	}
+
+	localFile, err := os.Open(localPath)
+	if err != nil {
+		return err
+	}
+	defer localFile.Close()
+
+	remoteFile, err := openContext(ctx, func() (*sftp.File, error) {
+		return c.sftpClient.Create(remotePath)
+	}, func() { c.removePartial(remotePath) })
+	if err != nil {
+		return err
+	}
+	stop := closeOnCancel(ctx, remoteFile)
+	_, err = io.Copy(remoteFile, localFile)
+	// A file closed on cancel is not closed again, as that would wait for
+	// the reply to the first close
+	if !stop() {
+		c.removePartial(remotePath)
+		return ctx.Err()
+	}
+	if closeErr := remoteFile.Close(); err == nil {
+		err = closeErr
+	}
+	return err
+}
+
+// DownloadFileContext copies remotePath to a local file. When ctx is done
+// the remote file is closed, which ends a read blocked on the server, and
+// the partial file is removed.
+func (c *SFTPGUIClient) DownloadFileContext(ctx context.Context, remotePath, localPath string) error {
+	if !c.IsConnected() {
+		return fmt.Errorf("not connected")
+	}
+
+	remoteFile, err := openContext(ctx, func() (*sftp.File, error) {
+		return c.sftpClient.Open(remotePath)
+	}, nil)
+	if err != nil {
+		return err
+	}
+	stop := closeOnCancel(ctx, remoteFile)
+
+	localFile, err := os.Create(localPath)
+	if err == nil {
+		_, err = io.Copy(localFile, remoteFile)
+		if closeErr := localFile.Close(); err == nil {
+			err = closeErr
+		}
+	}
+	if !stop() {
+		if localFile != nil {
+			os.Remove(localPath)
+		}
+		return ctx.Err()
+	}
+	remoteFile.Close()
+	return err
+}
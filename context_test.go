@@ -0,0 +1,132 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/pkg/sftp"
+)
+
+func TestSFTPGUIClient_TransferContext(t *testing.T) {
+	client, root := newTestClient(t)
+	local := t.TempDir()
+	writeTestFiles(t, local, map[string]string{"a.txt": "hello"})
+
+	ctx := context.Background()
+	if err := client.UploadFileContext(ctx, filepath.Join(local, "a.txt"), root+"/a.txt"); err != nil {
+		t.Fatalf("UploadFileContext failed: %v", err)
+	}
+	if err := client.DownloadFileContext(ctx, root+"/a.txt", filepath.Join(local, "back.txt")); err != nil {
+		t.Fatalf("DownloadFileContext failed: %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(local, "back.txt")); err != nil || string(data) != "hello" {
+		t.Errorf("Downloaded back.txt = %q, %v", data, err)
+	}
+
+	// Cancelled transfers leave no partial files behind
+	cancelled, cancel := context.WithCancel(ctx)
+	cancel()
+	err := client.UploadFileContext(cancelled, filepath.Join(local, "a.txt"), root+"/b.txt")
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled from upload, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "b.txt")); !os.IsNotExist(err) {
+		t.Errorf("A cancelled upload should remove the remote file, got %v", err)
+	}
+	err = client.DownloadFileContext(cancelled, root+"/a.txt", filepath.Join(local, "c.txt"))
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled from download, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(local, "c.txt")); !os.IsNotExist(err) {
+		t.Errorf("A cancelled download should remove the local file, got %v", err)
+	}
+
+	if _, err := client.ScanTreeContext(cancelled, []string{root}); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled from ScanTreeContext, got %v", err)
+	}
+}
+
+func TestOpenContext(t *testing.T) {
+	client, root := newTestClient(t)
+
+	// A file that opens after ctx is done is abandoned and closed
+	ctx, cancel := context.WithCancel(context.Background())
+	release := make(chan struct{})
+	abandoned := make(chan struct{})
+	_, err := openContext(ctx, func() (*sftp.File, error) {
+		cancel()
+		<-release
+		return client.sftpClient.Create(root + "/late.txt")
+	}, func() { close(abandoned) })
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+	close(release)
+	select {
+	case <-abandoned:
+	case <-time.After(5 * time.Second):
+		t.Error("A file opened late should be abandoned")
+	}
+}
+
+func TestSFTPGUIClient_RemovePartial(t *testing.T) {
+	client, root := newTestClient(t)
+	writeTestFiles(t, root, map[string]string{"a.part": "a", "b.part": "b"})
+
+	client.removePartial(root + "/a.part")
+	// Without a session of its own the main one is used
+	client.openChannel = nil
+	client.removePartial(root + "/b.part")
+
+	deadline := time.Now().Add(5 * time.Second)
+	for _, name := range []string{"a.part", "b.part"} {
+		for {
+			if _, err := os.Stat(filepath.Join(root, name)); os.IsNotExist(err) {
+				break
+			}
+			if time.Now().After(deadline) {
+				t.Errorf("%s should be removed in the background", name)
+				break
+			}
+			time.Sleep(10 * time.Millisecond)
+		}
+	}
+}
+
+func TestSession_CancelOperations(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping GUI test in short mode")
+	}
+	t.Setenv("HOME", t.TempDir())
+
+	app := newTestApp()
+	client, _ := newTestClient(t)
+	s := app.newSession(client)
+
+	started := make(chan struct{})
+	finished := make(chan struct{})
+	s.runBatch("Upload", "Uploaded", []string{"a", "b"}, func(ctx context.Context, name string) error {
+		close(started)
+		<-ctx.Done()
+		return ctx.Err()
+	}, func() { close(finished) })
+	<-started
+	app.ui.flush()
+	if !s.cancelBtn.Visible() {
+		t.Error("The Cancel button should show while an operation runs")
+	}
+	s.cancelBtn.OnTapped()
+
+	runUpdates(t, app, finished)
+	if s.cancelBtn.Visible() {
+		t.Error("The Cancel button should hide once the operation finished")
+	}
+	if !strings.Contains(s.logArea.Text, "Upload cancelled after 0 of 2 item(s)") {
+		t.Errorf("Log should report the cancelled batch, got %q", s.logArea.Text)
+	}
+}
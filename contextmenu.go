@@ -0,0 +1,172 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"runtime"
+	"strings"
+
+	"fyne.io/fyne/v2"
+)
+
+// contextMenu builds the right-click menu for the selected entries of
+// pane, which is the local pane or the remote pane of the session
+func (s *session) contextMenu(pane *filePane) *fyne.Menu {
+	remote := pane == s.remotePane
+	connected := s.client.IsConnected()
+	available := !remote || connected
+
+	entries := pane.selectedEntries()
+	single := len(entries) == 1
+	singleDir := single && (entries[0].IsDir || entries[0].LinkDir)
+	files := 0
+	for _, entry := range entries {
+		if !entry.IsDir && !entry.LinkDir {
+			files++
+		}
+	}
+
+	item := func(label string, enabled bool, action func()) *fyne.MenuItem {
+		menuItem := fyne.NewMenuItem(label, action)
+		menuItem.Disabled = !enabled
+		return menuItem
+	}
+
+	var items []*fyne.MenuItem
+	if remote {
+		items = append(items,
+			item("Open", available && singleDir, func() {
+				s.navigateRemote(path.Join(s.currentRemote, entries[0].Name), true)
+			}),
+			item("Edit", available && single && files == 1, s.onEdit),
+			item("Download", available && len(entries) > 0, s.onDownload),
+		)
+	} else {
+		items = append(items,
+			item("Open", singleDir || (files > 0 && files == len(entries)), func() {
+				if singleDir {
+					s.navigateLocal(filepath.Join(s.currentLocal, entries[0].Name), true)
+				} else {
+					s.onOpen()
+				}
+			}),
+			item("Upload", connected && len(entries) > 0, s.onUpload),
+		)
+	}
+
+	items = append(items,
+		fyne.NewMenuItemSeparator(),
+		item("Rename", available && single, s.onRename),
+		item("Delete", available && len(entries) > 0, s.onDelete),
+		item("Properties", available && single, s.onProperties),
+		fyne.NewMenuItemSeparator(),
+		item("Copy Path", available && len(entries) > 0, func() {
+			s.copyPaths(remote, entries)
+		}),
+		item("Open Terminal Here", available, func() {
+			// In the selected folder, otherwise in the current one
+			var name string
+			if singleDir {
+				name = entries[0].Name
+			}
+			if remote {
+				s.openTerminal(true, path.Join(s.currentRemote, name))
+			} else {
+				s.openTerminal(false, filepath.Join(s.currentLocal, name))
+			}
+		}),
+	)
+	return fyne.NewMenu("", items...)
+}
+
+// copyPaths puts the full paths of entries on the clipboard, one per line
+func (s *session) copyPaths(remote bool, entries []fileEntry) {
+	paths := make([]string, len(entries))
+	for i, entry := range entries {
+		if remote {
+			paths[i] = path.Join(s.currentRemote, entry.Name)
+		} else {
+			paths[i] = filepath.Join(s.currentLocal, entry.Name)
+		}
+	}
+	s.window.Clipboard().SetContent(strings.Join(paths, "\n"))
+	s.logMessage(fmt.Sprintf("Copied %d path(s) to the clipboard", len(paths)))
+}
+
+// openTerminal opens a terminal window in a local folder, or an SSH
+// login to the session's server that starts in a remote folder
+func (s *session) openTerminal(remote bool, dir string) {
+	var command []string
+	if remote {
+		command = []string{
+			"ssh", "-t", "-p", fmt.Sprint(s.port), s.user + "@" + s.host,
+			"cd " + shellQuote(dir) + ` && exec "$SHELL" -l`,
+		}
+	}
+
+	args, err := terminalArgs(runtime.GOOS, dir, command, exec.LookPath)
+	if err != nil {
+		s.showError(fmt.Sprintf("Cannot open a terminal: %v", err))
+		return
+	}
+	cmd := exec.Command(args[0], args[1:]...)
+	if !remote {
+		cmd.Dir = dir
+	}
+	if err := cmd.Start(); err != nil {
+		s.showError(fmt.Sprintf("Cannot open a terminal: %v", err))
+		return
+	}
+	go cmd.Wait()
+	s.logMessage(fmt.Sprintf("Opened terminal in %s", dir))
+}
+
+// linuxTerminals are tried in order when $TERMINAL is not set
+var linuxTerminals = []string{"x-terminal-emulator", "gnome-terminal", "konsole", "xfce4-terminal", "xterm"}
+
+// terminalArgs returns the command line opening a terminal window on goos
+// that runs command, or a shell in the local folder dir when command is
+// empty. lookPath finds the installed terminal emulators on Linux.
+func terminalArgs(goos, dir string, command []string, lookPath func(string) (string, error)) ([]string, error) {
+	switch goos {
+	case "darwin":
+		if len(command) == 0 {
+			return []string{"open", "-a", "Terminal", dir}, nil
+		}
+		quoted := make([]string, len(command))
+		for i, arg := range command {
+			quoted[i] = shellQuote(arg)
+		}
+		script := strings.NewReplacer(`\`, `\\`, `"`, `\"`).Replace(strings.Join(quoted, " "))
+		return []string{"osascript", "-e", `tell application "Terminal" to do script "` + script + `"`}, nil
+	case "windows":
+		// start opens a new console window; its first quoted argument is the title
+		if len(command) == 0 {
+			return []string{"cmd", "/c", "start", "", "/D", dir, "cmd"}, nil
+		}
+		return append([]string{"cmd", "/c", "start", ""}, command...), nil
+	case "linux", "freebsd", "openbsd", "netbsd":
+		candidates := linuxTerminals
+		if terminal := os.Getenv("TERMINAL"); terminal != "" {
+			candidates = append([]string{terminal}, candidates...)
+		}
+		for _, terminal := range candidates {
+			if _, err := lookPath(terminal); err != nil {
+				continue
+			}
+			if len(command) == 0 {
+				return []string{terminal}, nil
+			}
+			if terminal == "gnome-terminal" {
+				return append([]string{terminal, "--"}, command...), nil
+			}
+			return append([]string{terminal, "-e"}, command...), nil
+		}
+		return nil, fmt.Errorf("no terminal emulator found, set $TERMINAL")
+	default:
+		return nil, fmt.Errorf("unsupported operating system: %s", goos)
+	}
+}
@@ -0,0 +1,76 @@
+package main
+
+import (
+	"errors"
+	"reflect"
+	"testing"
+
+	"fyne.io/fyne/v2"
+)
+
+func TestTerminalArgs(t *testing.T) {
+	t.Setenv("TERMINAL", "")
+	installed := func(names ...string) func(string) (string, error) {
+		return func(name string) (string, error) {
+			for _, n := range names {
+				if n == name {
+					return "/usr/bin/" + name, nil
+				}
+			}
+			return "", errors.New("not found")
+		}
+	}
+	ssh := []string{"ssh", "-t", "user@host", "cd '/srv' && exec \"$SHELL\" -l"}
+
+	tests := []struct {
+		goos      string
+		command   []string
+		installed []string
+		want      []string
+	}{
+		{"linux", nil, []string{"xterm"}, []string{"xterm"}},
+		{"linux", ssh, []string{"konsole", "xterm"}, append([]string{"konsole", "-e"}, ssh...)},
+		{"linux", ssh, []string{"gnome-terminal"}, append([]string{"gnome-terminal", "--"}, ssh...)},
+		{"darwin", nil, nil, []string{"open", "-a", "Terminal", "/home/me"}},
+		{"darwin", []string{"ssh", "a b"}, nil, []string{"osascript", "-e", `tell application "Terminal" to do script "'ssh' 'a b'"`}},
+		{"windows", nil, nil, []string{"cmd", "/c", "start", "", "/D", "/home/me", "cmd"}},
+	}
+	for _, tt := range tests {
+		got, err := terminalArgs(tt.goos, "/home/me", tt.command, installed(tt.installed...))
+		if err != nil || !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("terminalArgs(%s, %q) = %q, %v; want %q", tt.goos, tt.command, got, err, tt.want)
+		}
+	}
+
+	if _, err := terminalArgs("linux", "/", nil, installed()); err == nil {
+		t.Error("Expected an error without a terminal emulator")
+	}
+}
+
+func TestSession_ContextMenu(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping GUI test in short mode")
+	}
+	t.Setenv("HOME", t.TempDir())
+
+	app := newTestApp()
+	app.localPane.setEntries([]fileEntry{{Name: "docs", IsDir: true}, {Name: "a.txt"}}, false)
+	app.localPane.showContextMenu(1, fyne.Position{})
+
+	enabled := make(map[string]bool)
+	for _, item := range app.home.contextMenu(app.localPane).Items {
+		enabled[item.Label] = !item.Disabled
+	}
+	want := map[string]bool{
+		"Open": true, "Upload": false, "Rename": true, "Delete": true,
+		"Properties": true, "Copy Path": true, "Open Terminal Here": true,
+	}
+	for label, state := range want {
+		if enabled[label] != state {
+			t.Errorf("%s enabled = %v, want %v", label, enabled[label], state)
+		}
+	}
+	if names := app.localPane.selectedNames(); len(names) != 1 || names[0] != "a.txt" {
+		t.Errorf("Right-clicked row should be selected, got %v", names)
+	}
+}
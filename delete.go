@@ -0,0 +1,337 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// deleteSummary counts what a recursive delete will remove
+type deleteSummary struct {
+	Files int
+	Dirs  int
+	Bytes int64
+}
+
+// Items returns the total number of entries
+func (s deleteSummary) Items() int {
+	return s.Files + s.Dirs
+}
+
+func (s deleteSummary) String() string {
+	return fmt.Sprintf("%d folder(s) and %d file(s), %s", s.Dirs, s.Files, humanSize(s.Bytes))
+}
+
+// ScanTree counts the entries and bytes of each path and everything below
+// it. Symlinks are counted as files and not followed.
+func (c *SFTPGUIClient) ScanTree(paths []string) (deleteSummary, error) {
+	return c.ScanTreeContext(context.Background(), paths)
+}
+
+// ScanTreeContext is ScanTree stopping with the error of ctx as soon as
+// ctx is done
+func (c *SFTPGUIClient) ScanTreeContext(ctx context.Context, paths []string) (deleteSummary, error) {
+	var summary deleteSummary
+	if !c.IsConnected() {
+		return summary, fmt.Errorf("not connected")
+	}
+
+	for _, p := range paths {
+		walker := c.sftpClient.Walk(p)
+		for walker.Step() {
+			if ctx.Err() != nil {
+				return summary, ctx.Err()
+			}
+			info := walker.Stat()
+			if info == nil {
+				if walker.Path() == p {
+					return summary, walker.Err()
+				}
+				continue
+			}
+			if info.IsDir() {
+				summary.Dirs++
+			} else {
+				summary.Files++
+				summary.Bytes += info.Size()
+			}
+		}
+	}
+	return summary, nil
+}
+
+// RemoveAll deletes p and everything below it, children before their
+// parents. progress is called once per entry with the result of deleting
+// it. Entries that fail are reported and skipped, and the delete stops
+// with the error of ctx as soon as ctx is done.
+func (c *SFTPGUIClient) RemoveAll(ctx context.Context, p string, progress func(p string, err error)) error {
+	if !c.IsConnected() {
+		return fmt.Errorf("not connected")
+	}
+
+	type item struct {
+		path  string
+		isDir bool
+	}
+	var items []item
+	failed := 0
+	walker := c.sftpClient.Walk(p)
+	for walker.Step() {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		info := walker.Stat()
+		if info == nil {
+			failed++
+			progress(walker.Path(), walker.Err())
+			continue
+		}
+		// An unreadable directory is still attempted below, which reports
+		// the failure if it is not empty
+		items = append(items, item{walker.Path(), info.IsDir()})
+	}
+
+	// Walk visits parents before their children, so go backwards
+	for i := len(items) - 1; i >= 0; i-- {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		var err error
+		if items[i].isDir {
+			err = c.sftpClient.RemoveDirectory(items[i].path)
+		} else {
+			err = c.sftpClient.Remove(items[i].path)
+		}
+		if err != nil {
+			failed++
+		}
+		progress(items[i].path, err)
+	}
+
+	if failed > 0 {
+		return fmt.Errorf("%d entries could not be deleted", failed)
+	}
+	return nil
+}
+
+// needsTypedConfirm reports whether a delete is large enough that the
+// user has to type its name to confirm it
+func (app *SFTPApp) needsTypedConfirm(summary deleteSummary) bool {
+	if summary.Dirs == 0 {
+		return false
+	}
+	return summary.Items() > app.settings.ConfirmDeleteItems ||
+		summary.Bytes > app.settings.ConfirmDeleteBytes
+}
+
+// onDelete deletes the selected entries of the active pane
+func (s *session) onDelete() {
+	if s.activePane == s.localPane {
+		s.deleteLocal()
+		return
+	}
+	if !s.client.IsConnected() {
+		return
+	}
+
+	names := s.remotePane.selectedNames()
+	if len(names) == 0 {
+		s.showError("Please select remote files to delete")
+		return
+	}
+
+	remoteDir := s.currentRemote
+	paths := make([]string, len(names))
+	for i, name := range names {
+		paths[i] = path.Join(remoteDir, name)
+	}
+
+	s.showProgress(fmt.Sprintf("Counting %d item(s) to delete...", len(names)))
+	ctx, done := s.startOperation()
+	go func() {
+		defer done()
+		summary, err := s.client.ScanTreeContext(ctx, paths)
+		s.hideProgress()
+		if ctx.Err() != nil {
+			s.logMessage("Delete cancelled")
+			return
+		}
+		if err != nil {
+			s.showError(fmt.Sprintf("Delete failed: %v", err))
+			return
+		}
+		s.do(func() {
+			s.confirmDelete(names, summary, func() {
+				s.deleteRemote(paths, summary)
+			})
+		})
+	}()
+}
+
+// confirmDelete asks before deleting names. Above the configured
+// thresholds the name has to be typed before Delete is enabled.
+func (s *session) confirmDelete(names []string, summary deleteSummary, onConfirm func()) {
+	message := fmt.Sprintf("Are you sure you want to delete '%s'?", names[0])
+	phrase := names[0]
+	if len(names) > 1 {
+		message = fmt.Sprintf("Are you sure you want to delete %d items?", len(names))
+		phrase = fmt.Sprintf("delete %d items", len(names))
+	}
+	if summary.Dirs > 0 {
+		message += fmt.Sprintf("\nThis removes %s.", summary)
+	}
+
+	if !s.needsTypedConfirm(summary) {
+		dialog.ShowConfirm("Confirm Delete", message, func(confirmed bool) {
+			if confirmed {
+				onConfirm()
+			}
+		}, s.window)
+		return
+	}
+
+	var d *dialog.CustomDialog
+	deleteBtn := widget.NewButton("Delete", func() {
+		d.Hide()
+		onConfirm()
+	})
+	deleteBtn.Importance = widget.DangerImportance
+	deleteBtn.Disable()
+
+	confirmEntry := widget.NewEntry()
+	confirmEntry.SetPlaceHolder(phrase)
+	confirmEntry.OnChanged = func(text string) {
+		if text == phrase {
+			deleteBtn.Enable()
+		} else {
+			deleteBtn.Disable()
+		}
+	}
+
+	content := container.NewVBox(
+		widget.NewLabel(message),
+		widget.NewLabel(fmt.Sprintf("Type '%s' to confirm:", phrase)),
+		confirmEntry,
+	)
+	d = dialog.NewCustomWithoutButtons("Confirm Delete", content, s.window)
+	d.SetButtons([]fyne.CanvasObject{
+		widget.NewButton("Cancel", func() { d.Hide() }),
+		deleteBtn,
+	})
+	d.Resize(fyne.NewSize(420, 0))
+	d.Show()
+	s.window.Canvas().Focus(confirmEntry)
+}
+
+// deleteRemote recursively deletes paths in the background, showing the
+// progress in a dialog that can cancel the delete. The Cancel button next
+// to the progress bar stops it too.
+func (s *session) deleteRemote(paths []string, summary deleteSummary) {
+	opCtx, done := s.startOperation()
+	ctx, cancel := context.WithCancel(opCtx)
+	progressBar := widget.NewProgressBar()
+	currentLabel := widget.NewLabel("")
+	currentLabel.Truncation = fyne.TextTruncateEllipsis
+
+	var d *dialog.CustomDialog
+	cancelBtn := widget.NewButton("Cancel", nil)
+	cancelBtn.OnTapped = func() {
+		cancelBtn.Disable()
+		cancel()
+	}
+	d = dialog.NewCustomWithoutButtons("Deleting...", container.NewVBox(progressBar, currentLabel), s.window)
+	d.SetButtons([]fyne.CanvasObject{cancelBtn})
+	d.Resize(fyne.NewSize(420, 0))
+	d.Show()
+
+	s.logMessage(fmt.Sprintf("Delete of %s started...", summary))
+
+	go func() {
+		defer done()
+		defer cancel()
+		total := summary.Items()
+		deleted, failed := 0, 0
+		lastRefresh := time.Now()
+		for _, p := range paths {
+			err := s.client.RemoveAll(ctx, p, func(entry string, err error) {
+				deleted++
+				if err != nil {
+					failed++
+					s.logMessage(fmt.Sprintf("Delete failed for %s: %v", entry, err))
+				}
+				// Avoid redrawing for every entry of large trees
+				if time.Since(lastRefresh) > 100*time.Millisecond {
+					value := float64(deleted) / float64(total)
+					s.do(func() {
+						progressBar.SetValue(value)
+						currentLabel.SetText(entry)
+					})
+					lastRefresh = time.Now()
+				}
+			})
+			if ctx.Err() != nil {
+				break
+			}
+			s.remoteChanged(p)
+			if err == nil {
+				s.logMessage(fmt.Sprintf("Deleted: %s", p))
+			}
+		}
+		s.do(d.Hide)
+
+		switch {
+		case ctx.Err() != nil:
+			s.logMessage(fmt.Sprintf("Delete cancelled after %d of %d item(s)", deleted-failed, total))
+		case failed > 0:
+			s.showError(fmt.Sprintf("Delete failed for %d of %d item(s)", failed, total))
+		default:
+			s.logMessage(fmt.Sprintf("Deleted %s", summary))
+		}
+		s.do(s.updateRemoteFiles)
+	}()
+}
+
+// deleteLocal moves the selected local entries to the trash, or deletes
+// them permanently where there is no trash
+func (s *session) deleteLocal() {
+	names := s.localPane.selectedNames()
+	if len(names) == 0 {
+		s.showError("Please select local files to delete")
+		return
+	}
+
+	what := fmt.Sprintf("'%s'", names[0])
+	if len(names) > 1 {
+		what = fmt.Sprintf("%d items", len(names))
+	}
+
+	localDir := s.currentLocal
+	if trashSupported {
+		dialog.ShowConfirm("Move to Trash", fmt.Sprintf("Move %s to the trash?", what), func(confirmed bool) {
+			if confirmed {
+				s.runBatch("Move to trash", "Moved to trash", names, func(_ context.Context, name string) error {
+					return moveToTrash(filepath.Join(localDir, name))
+				}, s.updateLocalFiles)
+			}
+		}, s.window)
+		return
+	}
+
+	dialog.ShowConfirm("Confirm Delete", fmt.Sprintf("Permanently delete %s? This cannot be undone.", what), func(confirmed bool) {
+		if confirmed {
+			s.runBatch("Delete", "Deleted", names, func(_ context.Context, name string) error {
+				return os.RemoveAll(filepath.Join(localDir, name))
+			}, s.updateLocalFiles)
+		}
+	}, s.window)
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestSFTPGUIClient_ScanTree(t *testing.T) {
+	client, root := newTestClient(t)
+	writeTestFiles(t, root, map[string]string{
+		"dir/a.txt":     "aaaa",
+		"dir/sub/b.txt": "bb",
+		"c.txt":         "c",
+	})
+
+	summary, err := client.ScanTree([]string{root + "/dir", root + "/c.txt"})
+	if err != nil {
+		t.Fatalf("ScanTree failed: %v", err)
+	}
+	want := deleteSummary{Files: 3, Dirs: 2, Bytes: 7}
+	if summary != want {
+		t.Errorf("Expected %+v, got %+v", want, summary)
+	}
+
+	if _, err := client.ScanTree([]string{root + "/missing"}); err == nil {
+		t.Error("Expected an error for a missing path")
+	}
+}
+
+func TestSFTPGUIClient_RemoveAll(t *testing.T) {
+	client, root := newTestClient(t)
+	writeTestFiles(t, root, map[string]string{
+		"dir/a.txt":         "a",
+		"dir/sub/b.txt":     "b",
+		"dir/sub/deep/c.go": "c",
+	})
+
+	var deleted []string
+	err := client.RemoveAll(context.Background(), root+"/dir", func(p string, err error) {
+		if err != nil {
+			t.Errorf("Delete of %s failed: %v", p, err)
+		}
+		deleted = append(deleted, p)
+	})
+	if err != nil {
+		t.Fatalf("RemoveAll failed: %v", err)
+	}
+	if len(deleted) != 6 {
+		t.Errorf("Expected 6 deleted entries, got %v", deleted)
+	}
+	if deleted[len(deleted)-1] != root+"/dir" {
+		t.Errorf("Expected the root to be deleted last, got %v", deleted)
+	}
+	if _, err := os.Stat(filepath.Join(root, "dir")); !os.IsNotExist(err) {
+		t.Error("dir should no longer exist")
+	}
+}
+
+func TestSFTPGUIClient_RemoveAllCancelled(t *testing.T) {
+	client, root := newTestClient(t)
+	writeTestFiles(t, root, map[string]string{"dir/a.txt": "a"})
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := client.RemoveAll(ctx, root+"/dir", func(string, error) {})
+	if !errors.Is(err, context.Canceled) {
+		t.Fatalf("Expected context.Canceled, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "dir", "a.txt")); err != nil {
+		t.Error("Nothing should be deleted after cancelling")
+	}
+}
+
+func TestNeedsTypedConfirm(t *testing.T) {
+	app := &SFTPApp{settings: defaultSettings()}
+	app.settings.ConfirmDeleteItems = 10
+	app.settings.ConfirmDeleteBytes = 1000
+
+	tests := []struct {
+		summary deleteSummary
+		want    bool
+	}{
+		{deleteSummary{Files: 50, Bytes: 5000}, false},
+		{deleteSummary{Dirs: 1, Files: 5, Bytes: 100}, false},
+		{deleteSummary{Dirs: 1, Files: 10, Bytes: 100}, true},
+		{deleteSummary{Dirs: 1, Files: 1, Bytes: 1001}, true},
+	}
+	for _, tt := range tests {
+		if got := app.needsTypedConfirm(tt.summary); got != tt.want {
+			t.Errorf("needsTypedConfirm(%+v) = %v, want %v", tt.summary, got, tt.want)
+		}
+	}
+}
+
+func TestSession_DeleteRemoteCancelled(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping GUI test in short mode")
+	}
+	t.Setenv("HOME", t.TempDir())
+
+	app := newTestApp()
+	client, root := newTestClient(t)
+	s := app.newSession(client)
+	files := make(map[string]string)
+	for i := 0; i < 200; i++ {
+		files[fmt.Sprintf("dir/file%d.txt", i)] = "x"
+	}
+	writeTestFiles(t, root, files)
+
+	// The delete is a session operation, so the shared Cancel button stops it
+	s.deleteRemote([]string{root + "/dir"}, deleteSummary{Dirs: 1, Files: 200})
+	app.ui.flush()
+	if !s.cancelBtn.Visible() {
+		t.Fatal("The Cancel button should show while a delete runs")
+	}
+	s.cancelBtn.OnTapped()
+
+	finished := make(chan struct{})
+	go func() {
+		defer close(finished)
+		for {
+			s.opsMu.Lock()
+			running := len(s.ops)
+			s.opsMu.Unlock()
+			if running == 0 {
+				return
+			}
+			time.Sleep(time.Millisecond)
+		}
+	}()
+	runUpdates(t, app, finished)
+	if !strings.Contains(s.logArea.Text, "Delete cancelled") {
+		t.Errorf("Log should report the cancelled delete, got %q", s.logArea.Text)
+	}
+	if _, err := os.Stat(filepath.Join(root, "dir")); err != nil {
+		t.Error("dir should still exist after cancelling")
+	}
+}
@@ -0,0 +1,559 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Status of an entry in a directory comparison. The local folder is the
+// left side and the remote folder the right side.
+const (
+	compareIdentical   = "Identical"
+	compareNewerLocal  = "Newer local"
+	compareNewerRemote = "Newer remote"
+	compareOnlyLocal   = "Only local"
+	compareOnlyRemote  = "Only remote"
+	// compareDiffers marks files of equal age with different sizes,
+	// entries of different types and folders with differing contents
+	compareDiffers = "Differs"
+)
+
+// dirCompareEntry is one path of a directory comparison. Local or Remote
+// is nil when the path only exists on the other side.
+type dirCompareEntry struct {
+	Path   string // relative, slash separated
+	Local  os.FileInfo
+	Remote os.FileInfo
+	Status string
+}
+
+// IsDir reports whether the entry is a folder on either side
+func (e *dirCompareEntry) IsDir() bool {
+	return (e.Local != nil && e.Local.IsDir()) || (e.Remote != nil && e.Remote.IsDir())
+}
+
+// dirComparison is the merged tree of a local and a remote folder
+type dirComparison struct {
+	entries  map[string]*dirCompareEntry
+	children map[string][]string // sorted child paths, "" is the root
+}
+
+// WalkTree lists every entry below root by relative slash-separated path.
+// Unreadable directories are skipped. The walk stops with the error of
+// ctx as soon as ctx is done.
+func (c *SFTPGUIClient) WalkTree(ctx context.Context, root string) (map[string]os.FileInfo, error) {
+	if !c.IsConnected() {
+		return nil, fmt.Errorf("not connected")
+	}
+
+	infos := make(map[string]os.FileInfo)
+	walker := c.sftpClient.Walk(root)
+	for walker.Step() {
+		if ctx.Err() != nil {
+			return nil, ctx.Err()
+		}
+
+		if walker.Err() != nil {
+			if walker.Path() == root {
+				return nil, walker.Err()
+			}
+			continue
+		}
+		if walker.Path() == root {
+			continue
+		}
+		rel := strings.TrimPrefix(strings.TrimPrefix(walker.Path(), root), "/")
+		infos[rel] = walker.Stat()
+	}
+	return infos, nil
+}
+
+// walkLocalTree lists every entry below a local root like WalkTree
+func walkLocalTree(ctx context.Context, root string) (map[string]os.FileInfo, error) {
+	infos := make(map[string]os.FileInfo)
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if err != nil {
+			if p == root {
+				return err
+			}
+			return nil
+		}
+		if p == root {
+			return nil
+		}
+		info, err := d.Info()
+		if err != nil {
+			return nil
+		}
+		rel, _ := filepath.Rel(root, p)
+		infos[filepath.ToSlash(rel)] = info
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return infos, nil
+}
+
+// compareTrees merges two listings made by WalkTree and walkLocalTree
+func compareTrees(local, remote map[string]os.FileInfo) *dirComparison {
+	cmp := &dirComparison{
+		entries:  make(map[string]*dirCompareEntry),
+		children: make(map[string][]string),
+	}
+	add := func(rel string) *dirCompareEntry {
+		if e, ok := cmp.entries[rel]; ok {
+			return e
+		}
+		e := &dirCompareEntry{Path: rel}
+		cmp.entries[rel] = e
+		parent := path.Dir(rel)
+		if parent == "." {
+			parent = ""
+		}
+		cmp.children[parent] = append(cmp.children[parent], rel)
+		return e
+	}
+	for rel, info := range local {
+		add(rel).Local = info
+	}
+	for rel, info := range remote {
+		add(rel).Remote = info
+	}
+
+	for _, e := range cmp.entries {
+		e.Status = compareStatus(e.Local, e.Remote)
+	}
+	// A folder differs when anything inside it does
+	for _, e := range cmp.entries {
+		if e.Status == compareIdentical {
+			continue
+		}
+		for parent := path.Dir(e.Path); parent != "."; parent = path.Dir(parent) {
+			if p := cmp.entries[parent]; p != nil && p.Status == compareIdentical {
+				p.Status = compareDiffers
+			}
+		}
+	}
+
+	for parent := range cmp.children {
+		sort.Strings(cmp.children[parent])
+	}
+	return cmp
+}
+
+// compareStatus compares the two sides of one path. Modification times
+// are compared to the second since SFTP does not carry finer times.
+func compareStatus(local, remote os.FileInfo) string {
+	switch {
+	case remote == nil:
+		return compareOnlyLocal
+	case local == nil:
+		return compareOnlyRemote
+	case local.IsDir() != remote.IsDir():
+		return compareDiffers
+	case local.IsDir():
+		return compareIdentical
+	}
+
+	localTime, remoteTime := local.ModTime().Unix(), remote.ModTime().Unix()
+	switch {
+	case localTime > remoteTime:
+		return compareNewerLocal
+	case localTime < remoteTime:
+		return compareNewerRemote
+	case local.Size() != remote.Size():
+		return compareDiffers
+	}
+	return compareIdentical
+}
+
+// expand returns the paths to transfer for a selection: the selected
+// paths plus everything inside selected folders that differs and exists
+// on the source side, parents before children
+func (cmp *dirComparison) expand(selected []string, fromLocal bool) []string {
+	seen := make(map[string]bool)
+	var paths []string
+	var visit func(rel string)
+	visit = func(rel string) {
+		e := cmp.entries[rel]
+		if e == nil || seen[rel] || e.Status == compareIdentical {
+			return
+		}
+		source := e.Remote
+		if fromLocal {
+			source = e.Local
+		}
+		if source == nil {
+			return
+		}
+		seen[rel] = true
+		paths = append(paths, rel)
+		if source.IsDir() {
+			for _, child := range cmp.children[rel] {
+				visit(child)
+			}
+		}
+	}
+	for _, rel := range selected {
+		visit(rel)
+	}
+	sort.Strings(paths)
+	return paths
+}
+
+// differences returns the top-most entries that are not identical
+func (cmp *dirComparison) differences() []string {
+	var paths []string
+	var visit func(parent string)
+	visit = func(parent string) {
+		for _, rel := range cmp.children[parent] {
+			switch e := cmp.entries[rel]; {
+			case e.Status == compareIdentical:
+			case e.Status == compareDiffers && e.Local != nil && e.Remote != nil && e.Local.IsDir() && e.Remote.IsDir():
+				visit(rel)
+			default:
+				paths = append(paths, rel)
+			}
+		}
+	}
+	visit("")
+	return paths
+}
+
+// compareDetails describes the size and modification time differences
+func compareDetails(e *dirCompareEntry) string {
+	if e.IsDir() || e.Local == nil || e.Remote == nil {
+		return ""
+	}
+	var parts []string
+	if delta := e.Local.Size() - e.Remote.Size(); delta > 0 {
+		parts = append(parts, "local "+humanSize(delta)+" larger")
+	} else if delta < 0 {
+		parts = append(parts, "remote "+humanSize(-delta)+" larger")
+	}
+	if delta := e.Local.ModTime().Sub(e.Remote.ModTime()).Truncate(time.Second); delta > 0 {
+		parts = append(parts, "local "+shortDuration(delta)+" newer")
+	} else if delta < 0 {
+		parts = append(parts, "remote "+shortDuration(-delta)+" newer")
+	}
+	return strings.Join(parts, ", ")
+}
+
+// shortDuration formats a duration in its largest whole unit
+func shortDuration(d time.Duration) string {
+	switch {
+	case d >= 24*time.Hour:
+		return fmt.Sprintf("%dd", d/(24*time.Hour))
+	case d >= time.Hour:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	case d >= time.Minute:
+		return fmt.Sprintf("%dm", d/time.Minute)
+	}
+	return fmt.Sprintf("%ds", d/time.Second)
+}
+
+// compareStatusIcon returns the icon shown next to a status
+func compareStatusIcon(status string) fyne.Resource {
+	switch status {
+	case compareIdentical:
+		return theme.ConfirmIcon()
+	case compareNewerLocal, compareOnlyLocal:
+		return theme.NavigateNextIcon()
+	case compareNewerRemote, compareOnlyRemote:
+		return theme.NavigateBackIcon()
+	}
+	return theme.WarningIcon()
+}
+
+// showDirCompare shows the comparison of a local and a remote folder,
+// defaulting to the current directories of both panes
+func (s *session) showDirCompare() {
+	if !s.client.IsConnected() {
+		s.showError("Please connect to a server before comparing folders")
+		return
+	}
+
+	localEntry := widget.NewEntry()
+	localEntry.SetText(s.currentLocal)
+	remoteEntry := widget.NewEntry()
+	remoteEntry.SetText(s.currentRemote)
+	hideIdentical := widget.NewCheck("Hide identical", nil)
+	hideIdentical.Checked = true
+	statusLabel := widget.NewLabel("")
+
+	var mu sync.Mutex
+	cmp := compareTrees(nil, nil)
+	checked := make(map[string]bool)
+	var localRoot, remoteRoot string
+
+	visibleChildren := func(parent string) []string {
+		mu.Lock()
+		defer mu.Unlock()
+		var ids []string
+		for _, rel := range cmp.children[parent] {
+			if hideIdentical.Checked && cmp.entries[rel].Status == compareIdentical {
+				continue
+			}
+			ids = append(ids, rel)
+		}
+		return ids
+	}
+
+	tree := widget.NewTree(
+		func(id widget.TreeNodeID) []widget.TreeNodeID {
+			return visibleChildren(id)
+		},
+		func(id widget.TreeNodeID) bool {
+			if id == "" {
+				return true
+			}
+			mu.Lock()
+			defer mu.Unlock()
+			e := cmp.entries[id]
+			return e != nil && e.IsDir()
+		},
+		func(branch bool) fyne.CanvasObject {
+			check := widget.NewCheck("", nil)
+			name := widget.NewLabel("template")
+			name.Truncation = fyne.TextTruncateEllipsis
+			status := widget.NewLabel("template")
+			details := widget.NewLabel("")
+			right := container.NewHBox(details, widget.NewIcon(theme.ConfirmIcon()), status)
+			return container.NewBorder(nil, nil, check, right, name)
+		},
+		nil,
+	)
+	tree.UpdateNode = func(id widget.TreeNodeID, branch bool, obj fyne.CanvasObject) {
+		mu.Lock()
+		e := cmp.entries[id]
+		isChecked := checked[id]
+		mu.Unlock()
+		if e == nil {
+			return
+		}
+
+		row := obj.(*fyne.Container)
+		name := row.Objects[0].(*widget.Label)
+		check := row.Objects[1].(*widget.Check)
+		right := row.Objects[2].(*fyne.Container)
+
+		name.SetText(path.Base(e.Path))
+		check.OnChanged = nil
+		check.SetChecked(isChecked)
+		check.OnChanged = func(on bool) {
+			mu.Lock()
+			checked[id] = on
+			mu.Unlock()
+		}
+		if e.Status == compareIdentical {
+			check.Disable()
+		} else {
+			check.Enable()
+		}
+		right.Objects[0].(*widget.Label).SetText(compareDetails(e))
+		right.Objects[1].(*widget.Icon).SetResource(compareStatusIcon(e.Status))
+		right.Objects[2].(*widget.Label).SetText(e.Status)
+	}
+	hideIdentical.OnChanged = func(bool) { tree.Refresh() }
+
+	// cancel stops the running comparison, which is also stopped by the
+	// Cancel button of the main window
+	var cancel context.CancelFunc
+	var compareBtn *widget.Button
+	runCompare := func() {
+		if cancel != nil {
+			cancel()
+		}
+		opCtx, done := s.startOperation()
+		ctx, compareCancel := context.WithCancel(opCtx)
+		cancel = compareCancel
+		newLocal, newRemote := localEntry.Text, path.Clean(remoteEntry.Text)
+		compareBtn.Disable()
+		statusLabel.SetText("Comparing...")
+
+		go func() {
+			defer done()
+			defer compareCancel()
+			defer s.do(compareBtn.Enable)
+			setStatus := func(status string) {
+				s.do(func() { statusLabel.SetText(status) })
+			}
+			local, err := walkLocalTree(ctx, newLocal)
+			if err != nil {
+				if ctx.Err() == nil {
+					setStatus(fmt.Sprintf("Cannot read %s: %v", newLocal, err))
+				}
+				return
+			}
+			remote, err := s.client.WalkTree(ctx, newRemote)
+			if err != nil {
+				if ctx.Err() == nil {
+					setStatus(fmt.Sprintf("Cannot read %s: %v", newRemote, err))
+				}
+				return
+			}
+
+			result := compareTrees(local, remote)
+			mu.Lock()
+			cmp, localRoot, remoteRoot = result, newLocal, newRemote
+			checked = make(map[string]bool)
+			mu.Unlock()
+
+			counts := make(map[string]int)
+			for _, e := range result.entries {
+				if !e.IsDir() || e.Local == nil || e.Remote == nil {
+					counts[e.Status]++
+				}
+			}
+			setStatus(fmt.Sprintf("%d identical, %d newer local, %d newer remote, %d only local, %d only remote, %d differ",
+				counts[compareIdentical], counts[compareNewerLocal], counts[compareNewerRemote],
+				counts[compareOnlyLocal], counts[compareOnlyRemote], counts[compareDiffers]))
+			s.do(tree.Refresh)
+		}()
+	}
+	compareBtn = widget.NewButtonWithIcon("Compare", theme.ViewRefreshIcon(), runCompare)
+
+	selectDifferences := widget.NewButton("Select Differences", func() {
+		mu.Lock()
+		for _, rel := range cmp.differences() {
+			checked[rel] = true
+		}
+		mu.Unlock()
+		tree.Refresh()
+	})
+	clearSelection := widget.NewButton("Clear", func() {
+		mu.Lock()
+		checked = make(map[string]bool)
+		mu.Unlock()
+		tree.Refresh()
+	})
+
+	transfer := func(toRemote bool) {
+		mu.Lock()
+		var selected []string
+		for rel, on := range checked {
+			if on {
+				selected = append(selected, rel)
+			}
+		}
+		paths := cmp.expand(selected, toRemote)
+		entries, local, remote := cmp.entries, localRoot, remoteRoot
+		mu.Unlock()
+
+		if len(paths) == 0 {
+			statusLabel.SetText("Nothing to transfer, select entries that exist on the source side")
+			return
+		}
+
+		run := func() {
+			action, done := "Pull", "Pulled"
+			if toRemote {
+				action, done = "Push", "Pushed"
+			}
+			s.runBatch(action, done, paths, func(ctx context.Context, rel string) error {
+				e := entries[rel]
+				localPath := filepath.Join(local, filepath.FromSlash(rel))
+				remotePath := path.Join(remote, rel)
+				if toRemote {
+					return s.pushEntry(ctx, localPath, remotePath, e.Local)
+				}
+				return s.pullEntry(ctx, remotePath, localPath, e.Remote)
+			}, func() {
+				s.onRefresh()
+				runCompare()
+			})
+		}
+		if !toRemote {
+			run()
+			return
+		}
+
+		var size int64
+		for _, rel := range paths {
+			if info := entries[rel].Local; !info.IsDir() {
+				size += info.Size()
+			}
+		}
+		s.checkDiskSpace(remote, size, run)
+	}
+	pushBtn := widget.NewButtonWithIcon("Push to Remote", theme.UploadIcon(), func() { transfer(true) })
+	pullBtn := widget.NewButtonWithIcon("Pull to Local", theme.DownloadIcon(), func() { transfer(false) })
+
+	form := widget.NewForm(
+		widget.NewFormItem("Local folder", localEntry),
+		widget.NewFormItem("Remote folder", remoteEntry),
+	)
+	content := container.NewBorder(
+		container.NewVBox(form, container.NewHBox(compareBtn, hideIdentical), statusLabel),
+		container.NewHBox(selectDifferences, clearSelection, layout.NewSpacer(), pullBtn, pushBtn),
+		nil, nil,
+		tree,
+	)
+
+	d := dialog.NewCustom("Compare Folders", "Close", content, s.window)
+	d.SetOnClosed(func() {
+		if cancel != nil {
+			cancel()
+			cancel = nil
+		}
+	})
+	d.Resize(fyne.NewSize(900, 650))
+	d.Show()
+	runCompare()
+}
+
+// pushEntry copies one compared entry to the server, keeping its
+// modification time so the next comparison sees it as identical
+func (s *session) pushEntry(ctx context.Context, localPath, remotePath string, info os.FileInfo) error {
+	defer s.remoteChanged(remotePath)
+	if info.IsDir() {
+		return s.client.sftpClient.MkdirAll(remotePath)
+	}
+	if err := s.client.sftpClient.MkdirAll(path.Dir(remotePath)); err != nil {
+		return err
+	}
+	if err := s.uploadFile(ctx, localPath, remotePath); err != nil {
+		return s.explainUploadError(remotePath, err)
+	}
+	if info.Mode()&os.ModeSymlink != 0 && !s.settings.FollowLinks {
+		return nil
+	}
+	return s.client.sftpClient.Chtimes(remotePath, time.Now(), info.ModTime())
+}
+
+// pullEntry copies one compared entry from the server like pushEntry
+func (s *session) pullEntry(ctx context.Context, remotePath, localPath string, info os.FileInfo) error {
+	if info.IsDir() {
+		return os.MkdirAll(localPath, 0755)
+	}
+	if err := os.MkdirAll(filepath.Dir(localPath), 0755); err != nil {
+		return err
+	}
+	if err := s.downloadFile(ctx, remotePath, localPath); err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSymlink != 0 && !s.settings.FollowLinks {
+		return nil
+	}
+	return os.Chtimes(localPath, time.Now(), info.ModTime())
+}
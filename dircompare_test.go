@@ -0,0 +1,109 @@
+package main
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"testing"
+	"time"
+)
+
+func TestCompareTrees(t *testing.T) {
+	client, remoteRoot := newTestClient(t)
+	localRoot := t.TempDir()
+	writeTestFiles(t, localRoot, map[string]string{
+		"same.txt":       "same",
+		"newer.txt":      "local",
+		"older.txt":      "local",
+		"size.txt":       "longer",
+		"local-only.txt": "x",
+		"dir/same.txt":   "same",
+		"dir/new.txt":    "x",
+	})
+	writeTestFiles(t, remoteRoot, map[string]string{
+		"same.txt":        "same",
+		"newer.txt":       "remote",
+		"older.txt":       "remote",
+		"size.txt":        "short",
+		"remote-only.txt": "x",
+		"dir/same.txt":    "same",
+	})
+
+	base := time.Now().Add(-time.Hour).Truncate(time.Second)
+	times := map[string][2]time.Time{
+		"same.txt":     {base, base},
+		"newer.txt":    {base.Add(time.Minute), base},
+		"older.txt":    {base, base.Add(time.Minute)},
+		"size.txt":     {base, base},
+		"dir/same.txt": {base, base},
+	}
+	for name, ts := range times {
+		os.Chtimes(filepath.Join(localRoot, name), ts[0], ts[0])
+		os.Chtimes(filepath.Join(remoteRoot, name), ts[1], ts[1])
+	}
+
+	local, err := walkLocalTree(context.Background(), localRoot)
+	if err != nil {
+		t.Fatalf("walkLocalTree failed: %v", err)
+	}
+	remote, err := client.WalkTree(context.Background(), remoteRoot)
+	if err != nil {
+		t.Fatalf("WalkTree failed: %v", err)
+	}
+
+	cmp := compareTrees(local, remote)
+	want := map[string]string{
+		"same.txt":        compareIdentical,
+		"newer.txt":       compareNewerLocal,
+		"older.txt":       compareNewerRemote,
+		"size.txt":        compareDiffers,
+		"local-only.txt":  compareOnlyLocal,
+		"remote-only.txt": compareOnlyRemote,
+		"dir":             compareDiffers,
+		"dir/same.txt":    compareIdentical,
+		"dir/new.txt":     compareOnlyLocal,
+	}
+	for rel, status := range want {
+		e := cmp.entries[rel]
+		if e == nil {
+			t.Errorf("Missing entry %s", rel)
+			continue
+		}
+		if e.Status != status {
+			t.Errorf("%s: got %q, want %q", rel, e.Status, status)
+		}
+	}
+	if len(cmp.entries) != len(want) {
+		t.Errorf("Expected %d entries, got %d", len(want), len(cmp.entries))
+	}
+
+	differences := cmp.differences()
+	wantDifferences := []string{"dir/new.txt", "local-only.txt", "newer.txt", "older.txt", "remote-only.txt", "size.txt"}
+	if !reflect.DeepEqual(differences, wantDifferences) {
+		t.Errorf("differences() = %v, want %v", differences, wantDifferences)
+	}
+
+	push := cmp.expand([]string{"dir", "remote-only.txt", "same.txt"}, true)
+	if !reflect.DeepEqual(push, []string{"dir", "dir/new.txt"}) {
+		t.Errorf("expand() for push = %v", push)
+	}
+	pull := cmp.expand([]string{"dir", "remote-only.txt"}, false)
+	if !reflect.DeepEqual(pull, []string{"dir", "remote-only.txt"}) {
+		t.Errorf("expand() for pull = %v", pull)
+	}
+}
+
+func TestShortDuration(t *testing.T) {
+	tests := map[time.Duration]string{
+		5 * time.Second:  "5s",
+		90 * time.Second: "1m",
+		3 * time.Hour:    "3h",
+		50 * time.Hour:   "2d",
+	}
+	for d, want := range tests {
+		if got := shortDuration(d); got != want {
+			t.Errorf("shortDuration(%v) = %q, want %q", d, got, want)
+		}
+	}
+}
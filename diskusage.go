@@ -0,0 +1,162 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+
+	"fyne.io/fyne/v2/dialog"
+)
+
+// diskSpace is the capacity of the filesystem holding a remote path
+type diskSpace struct {
+	Total uint64
+	Free  uint64 // available to unprivileged users
+}
+
+func (d diskSpace) String() string {
+	return fmt.Sprintf("%s free of %s", humanSize(int64(d.Free)), humanSize(int64(d.Total)))
+}
+
+// DiskSpace reports the free and total space of the filesystem holding p.
+// It needs the statvfs@openssh.com extension on the server.
+func (c *SFTPGUIClient) DiskSpace(p string) (diskSpace, error) {
+	if !c.IsConnected() {
+		return diskSpace{}, fmt.Errorf("not connected")
+	}
+
+	stat, err := c.sftpClient.StatVFS(p)
+	if err != nil {
+		return diskSpace{}, err
+	}
+	return diskSpace{Total: stat.TotalSpace(), Free: stat.Frsize * stat.Bavail}, nil
+}
+
+// updateDiskSpace shows the space left in the current remote directory
+// in the footer while the session is selected. It is blank when the
+// server cannot report it. Replies to earlier lookups, or for a folder
+// that was left meanwhile, are dropped.
+func (s *session) updateDiskSpace() {
+	dir := s.currentRemote
+	s.diskSpaceGen++
+	gen := s.diskSpaceGen
+	go func() {
+		text := ""
+		if space, err := s.client.DiskSpace(dir); err == nil {
+			text = "💾 " + space.String()
+		}
+		s.do(func() {
+			if gen != s.diskSpaceGen || dir != s.currentRemote {
+				return
+			}
+			s.diskSpace = text
+			if s.current == s {
+				s.diskSpaceLabel.SetText(s.diskSpace)
+			}
+		})
+	}()
+}
+
+// checkDiskSpace looks up the space left in the remote directory dir in
+// the background. proceed then runs on the UI goroutine, right away when
+// size bytes fit and otherwise only after the user confirms.
+func (s *session) checkDiskSpace(dir string, size int64, proceed func()) {
+	go func() {
+		space, err := s.client.DiskSpace(dir)
+		s.do(func() {
+			if err != nil || size <= int64(space.Free) {
+				proceed()
+				return
+			}
+			dialog.ShowConfirm("Not Enough Space",
+				fmt.Sprintf("The transfer needs %s but only %s is free on the server.\nStart it anyway?",
+					humanSize(size), humanSize(int64(space.Free))),
+				func(start bool) {
+					if start {
+						proceed()
+					}
+				}, s.window)
+		})
+	}()
+}
+
+// explainUploadError adds the likely cause to a failed upload when the
+// remote disk has run full, since servers report it as a generic failure
+func (s *session) explainUploadError(remotePath string, err error) error {
+	if space, spaceErr := s.client.DiskSpace(path.Dir(remotePath)); spaceErr == nil && space.Free == 0 {
+		return fmt.Errorf("%v (the remote disk is full)", err)
+	}
+	return err
+}
+
+// onFolderSize walks the selected remote folders, or the current one when
+// nothing is selected, and reports their total size
+func (s *session) onFolderSize() {
+	if !s.client.IsConnected() {
+		return
+	}
+
+	names := s.remotePane.selectedNames()
+	paths := []string{s.currentRemote}
+	label := s.currentRemote
+	if len(names) > 0 {
+		paths = paths[:0]
+		for _, name := range names {
+			paths = append(paths, path.Join(s.currentRemote, name))
+		}
+		label = fmt.Sprintf("%d selected item(s)", len(names))
+		if len(names) == 1 {
+			label = paths[0]
+		}
+	}
+
+	s.showProgress(fmt.Sprintf("Calculating size of %s...", label))
+	ctx, done := s.startOperation()
+	go func() {
+		defer done()
+		summary, err := s.client.ScanTreeContext(ctx, paths)
+		s.hideProgress()
+		if ctx.Err() != nil {
+			s.logMessage(fmt.Sprintf("Calculating size of %s cancelled", label))
+			return
+		}
+		if err != nil {
+			s.showError(fmt.Sprintf("Calculating size failed: %v", err))
+			return
+		}
+		s.logMessage(fmt.Sprintf("Size of %s: %s", label, summary))
+		s.do(func() {
+			dialog.ShowInformation("Folder Size",
+				fmt.Sprintf("%s\n\n%s in %d file(s) and %d folder(s)", label, humanSize(summary.Bytes), summary.Files, summary.Dirs),
+				s.window)
+		})
+	}()
+}
+
+// localTreeSize returns the total size of local files and of everything
+// inside local folders. Paths that cannot be read are skipped; only ctx
+// being done makes it fail.
+func localTreeSize(ctx context.Context, paths []string) (int64, error) {
+	var total int64
+	for _, p := range paths {
+		info, err := os.Stat(p)
+		if err != nil {
+			continue
+		}
+		if !info.IsDir() {
+			total += info.Size()
+			continue
+		}
+		infos, _ := walkLocalTree(ctx, p)
+		if ctx.Err() != nil {
+			return 0, ctx.Err()
+		}
+		for _, info := range infos {
+			if info.Mode().IsRegular() {
+				total += info.Size()
+			}
+		}
+	}
+	return total, nil
+}
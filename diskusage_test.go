@@ -0,0 +1,36 @@
+package main
+
+import "testing"
+
+func TestSFTPGUIClient_DiskSpace(t *testing.T) {
+	client, root := newTestClient(t)
+
+	space, err := client.DiskSpace(root)
+	if err != nil {
+		t.Skipf("Test server does not support statvfs: %v", err)
+	}
+	if space.Total == 0 || space.Free > space.Total {
+		t.Errorf("Implausible disk space: %+v", space)
+	}
+}
+
+func TestSession_CheckDiskSpace(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping GUI test in short mode")
+	}
+	t.Setenv("HOME", t.TempDir())
+
+	app := newTestApp()
+	client, root := newTestClient(t)
+	s := app.newSession(client)
+
+	// The server is asked in the background and proceed runs as a UI update
+	proceeded := make(chan struct{})
+	s.checkDiskSpace(root, 1, func() { close(proceeded) })
+	select {
+	case <-proceeded:
+		t.Fatal("proceed should not run on the calling goroutine")
+	default:
+	}
+	runUpdates(t, app, proceeded)
+}
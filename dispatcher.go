@@ -0,0 +1,53 @@
+package main
+
+import "sync"
+
+// uiDispatcher queues the UI updates of background operations and has
+// them run on the Fyne event goroutine, one at a time, in the order they
+// were made and never while an event handler runs. post hands a run of
+// the queue to that goroutine; without it the updates wait for flush.
+type uiDispatcher struct {
+	mu      sync.Mutex
+	pending []func()
+	post    func(func())
+}
+
+func newUIDispatcher(post func(func())) *uiDispatcher {
+	return &uiDispatcher{post: post}
+}
+
+// do queues update. It never blocks, so updates may queue further ones.
+func (d *uiDispatcher) do(update func()) {
+	d.mu.Lock()
+	d.pending = append(d.pending, update)
+	first := len(d.pending) == 1
+	d.mu.Unlock()
+
+	// A run already posted picks up the update
+	if first && d.post != nil {
+		d.post(d.flush)
+	}
+}
+
+// flush runs the queued updates, including those they queue. It must be
+// called on the Fyne event goroutine.
+func (d *uiDispatcher) flush() {
+	for {
+		d.mu.Lock()
+		updates := d.pending
+		d.pending = nil
+		d.mu.Unlock()
+		if len(updates) == 0 {
+			return
+		}
+		for _, update := range updates {
+			update()
+		}
+	}
+}
+
+// do runs update on the Fyne event goroutine. Background operations use
+// it for everything that touches widgets or pane state.
+func (app *SFTPApp) do(update func()) {
+	app.ui.do(update)
+}
@@ -0,0 +1,166 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"path/filepath"
+	"strings"
+	"sync"
+	"testing"
+	"time"
+)
+
+// newTestApp creates an application for GUI tests. The test goroutine
+// stands in for the Fyne event goroutine: handlers are called from it
+// directly and the updates of background operations only run when it
+// calls runUpdates.
+func newTestApp() *SFTPApp {
+	app := NewSFTPApp()
+	app.ui.post = nil
+	return app
+}
+
+// runUpdates runs the queued UI updates of app on the test goroutine
+// until done is closed, the way the Fyne event goroutine runs them
+// between handlers
+func runUpdates(t *testing.T, app *SFTPApp, done <-chan struct{}) {
+	t.Helper()
+	timeout := time.After(10 * time.Second)
+	tick := time.NewTicker(time.Millisecond)
+	defer tick.Stop()
+	for {
+		app.ui.flush()
+		select {
+		case <-done:
+			app.ui.flush()
+			return
+		case <-timeout:
+			t.Fatal("Background operation did not finish")
+		case <-tick.C:
+		}
+	}
+}
+
+func TestUIDispatcher(t *testing.T) {
+	runs := make(chan func(), 100)
+	d := newUIDispatcher(func(run func()) { runs <- run })
+
+	var order []int
+	var wg sync.WaitGroup
+	for g := 0; g < 8; g++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := 0; i < 100; i++ {
+				d.do(func() { order = append(order, len(order)) })
+			}
+		}()
+	}
+	wg.Wait()
+
+	// Updates queued by updates run after them instead of blocking
+	nested := false
+	d.do(func() {
+		d.do(func() { nested = true })
+	})
+	for len(runs) > 0 {
+		(<-runs)()
+	}
+
+	if len(order) != 800 {
+		t.Errorf("Expected 800 updates, got %d", len(order))
+	}
+	for i, n := range order {
+		if n != i {
+			t.Fatalf("Updates ran out of order: update %d saw %d before it", i, n)
+		}
+	}
+	if !nested {
+		t.Error("An update queued by an update should run")
+	}
+}
+
+func TestSFTPGUIClient_ConcurrentUse(t *testing.T) {
+	client, root := newTestClient(t)
+	writeTestFiles(t, root, map[string]string{"a.txt": "a", "dir/b.txt": "b"})
+
+	var wg sync.WaitGroup
+	for i := 0; i < 8; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				if !client.IsConnected() {
+					t.Error("Client should stay connected")
+					return
+				}
+				if _, err := client.GetFiles(root); err != nil {
+					t.Errorf("GetFiles failed: %v", err)
+					return
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	c := NewSFTPGUIClient()
+	wg.Add(2)
+	go func() { defer wg.Done(); c.Disconnect() }()
+	go func() { defer wg.Done(); c.IsConnected() }()
+	wg.Wait()
+}
+
+func TestSession_BackgroundOperations(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping GUI test in short mode")
+	}
+	t.Setenv("HOME", t.TempDir())
+
+	app := newTestApp()
+	client, remoteRoot := newTestClient(t)
+	s := app.newSession(client)
+
+	localRoot := t.TempDir()
+	files := make(map[string]string)
+	var paths []string
+	for i := 0; i < 5; i++ {
+		name := fmt.Sprintf("file%d.txt", i)
+		files[name] = name
+		paths = append(paths, filepath.Join(localRoot, name))
+	}
+	writeTestFiles(t, localRoot, files)
+
+	// An upload, a listing and log lines from other goroutines at once
+	finished := make(chan struct{})
+	s.currentRemote = remoteRoot
+	s.checkDiskSpace(remoteRoot, 0, func() {
+		s.runBatch("Upload", "Uploaded", paths, func(ctx context.Context, localPath string) error {
+			return s.uploadTree(ctx, localPath, remoteRoot+"/"+filepath.Base(localPath))
+		}, func() { close(finished) })
+	})
+	s.reloadRemoteFiles()
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func(i int) {
+			defer wg.Done()
+			s.logMessage(fmt.Sprintf("worker %d", i))
+		}(i)
+	}
+	wg.Wait()
+	runUpdates(t, app, finished)
+
+	s.reloadRemoteFiles()
+	waitForRemoteListing(t, s)
+	for i := 0; i < 4; i++ {
+		if !strings.Contains(s.logArea.Text, fmt.Sprintf("worker %d", i)) {
+			t.Errorf("Log is missing the line of worker %d", i)
+		}
+	}
+	if !strings.Contains(s.logArea.Text, "Uploaded: "+paths[4]) {
+		t.Error("Log is missing the upload")
+	}
+	if len(s.remotePane.all) != 6 {
+		t.Errorf("Expected 5 uploaded files and .., got %d entries", len(s.remotePane.all))
+	}
+}
@@ -0,0 +1,161 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	"fyne.io/fyne/v2"
+)
+
+// uploadTree uploads a local file, or a folder with everything inside it.
+// It stops with the error of ctx as soon as ctx is done.
+func (s *session) uploadTree(ctx context.Context, localPath, remotePath string) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	info, err := os.Lstat(localPath)
+	if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSymlink != 0 && s.settings.FollowLinks {
+		if info, err = os.Stat(localPath); err != nil {
+			return err
+		}
+	}
+	if !info.IsDir() {
+		return s.uploadFile(ctx, localPath, remotePath)
+	}
+
+	if err := s.client.sftpClient.MkdirAll(remotePath); err != nil {
+		return err
+	}
+	entries, err := os.ReadDir(localPath)
+	if err != nil {
+		return err
+	}
+	for _, entry := range entries {
+		if err := s.uploadTree(ctx, filepath.Join(localPath, entry.Name()), path.Join(remotePath, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// downloadTree downloads a remote file, or a folder with everything inside
+// it, like uploadTree
+func (s *session) downloadTree(ctx context.Context, remotePath, localPath string) error {
+	if ctx.Err() != nil {
+		return ctx.Err()
+	}
+	info, err := s.client.sftpClient.Lstat(remotePath)
+	if err != nil {
+		return err
+	}
+	if info.Mode()&os.ModeSymlink != 0 && s.settings.FollowLinks {
+		if info, err = s.client.sftpClient.Stat(remotePath); err != nil {
+			return err
+		}
+	}
+	if !info.IsDir() {
+		return s.downloadFile(ctx, remotePath, localPath)
+	}
+
+	if err := os.MkdirAll(localPath, 0755); err != nil {
+		return err
+	}
+	entries, err := s.client.sftpClient.ReadDirContext(ctx, remotePath)
+	if err != nil {
+		return cancelled(ctx, err)
+	}
+	for _, entry := range entries {
+		if err := s.downloadTree(ctx, path.Join(remotePath, entry.Name()), filepath.Join(localPath, entry.Name())); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// uploadPaths sizes local files and folders in the background, checks
+// the space left in remoteDir and then uploads them as one batch
+func (s *session) uploadPaths(localPaths []string, remoteDir string) {
+	s.showProgress(fmt.Sprintf("Calculating size of %d item(s)...", len(localPaths)))
+	ctx, done := s.startOperation()
+	go func() {
+		defer done()
+		size, err := localTreeSize(ctx, localPaths)
+		s.hideProgress()
+		if err != nil {
+			s.logMessage("Upload cancelled")
+			return
+		}
+		s.checkDiskSpace(remoteDir, size, func() {
+			s.runBatch("Upload", "Uploaded", localPaths, func(ctx context.Context, localPath string) error {
+				remotePath := path.Join(remoteDir, filepath.Base(localPath))
+				defer s.remoteChanged(remotePath)
+				if err := s.uploadTree(ctx, localPath, remotePath); err != nil {
+					return s.explainUploadError(remotePath, err)
+				}
+				return nil
+			}, s.updateRemoteFiles)
+		})
+	}()
+}
+
+// downloadPaths downloads remote files and folders into localDir as one batch
+func (s *session) downloadPaths(remotePaths []string, localDir string) {
+	s.runBatch("Download", "Downloaded", remotePaths, func(ctx context.Context, remotePath string) error {
+		return s.downloadTree(ctx, remotePath, filepath.Join(localDir, path.Base(remotePath)))
+	}, s.updateLocalFiles)
+}
+
+// transferDropped uploads entries dragged from the local pane onto the
+// remote pane and downloads those dragged the other way. Dropping on a
+// folder row transfers into that folder, anywhere else into the current
+// folder of the pane.
+func (s *session) transferDropped(src *filePane, entries []fileEntry, dst *filePane, target *fileEntry) {
+	if !s.client.IsConnected() {
+		return
+	}
+
+	var subdir string
+	if target != nil && (target.IsDir || target.LinkDir) {
+		subdir = target.Name
+	}
+
+	switch {
+	case src == s.localPane && dst == s.remotePane:
+		paths := make([]string, len(entries))
+		for i, entry := range entries {
+			paths[i] = filepath.Join(s.currentLocal, entry.Name)
+		}
+		s.uploadPaths(paths, path.Join(s.currentRemote, subdir))
+	case src == s.remotePane && dst == s.localPane:
+		paths := make([]string, len(entries))
+		for i, entry := range entries {
+			paths[i] = path.Join(s.currentRemote, entry.Name)
+		}
+		s.downloadPaths(paths, filepath.Join(s.currentLocal, subdir))
+	}
+}
+
+// onFilesDropped uploads files and folders dropped onto the window from
+// the desktop into the current remote folder
+func (s *session) onFilesDropped(uris []fyne.URI) {
+	var paths []string
+	for _, uri := range uris {
+		if uri.Scheme() == "file" {
+			paths = append(paths, uri.Path())
+		}
+	}
+	if len(paths) == 0 {
+		return
+	}
+	if !s.client.IsConnected() {
+		s.showError(fmt.Sprintf("Connect to a server to upload %d dropped item(s)", len(paths)))
+		return
+	}
+	s.uploadPaths(paths, s.currentRemote)
+}
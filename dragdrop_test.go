@@ -0,0 +1,55 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+)
+
+func TestSession_UploadDownloadTree(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping GUI test in short mode")
+	}
+	t.Setenv("HOME", t.TempDir())
+
+	app := newTestApp()
+	client, remoteRoot := newTestClient(t)
+	s := app.newSession(client)
+
+	localRoot := t.TempDir()
+	writeTestFiles(t, localRoot, map[string]string{
+		"site/index.html":    "<html>",
+		"site/css/style.css": "body {}",
+		"site/img/.gitkeep":  "",
+	})
+
+	if err := s.uploadTree(context.Background(), filepath.Join(localRoot, "site"), path.Join(remoteRoot, "site")); err != nil {
+		t.Fatalf("uploadTree failed: %v", err)
+	}
+	if data, err := os.ReadFile(filepath.Join(remoteRoot, "site", "css", "style.css")); err != nil || string(data) != "body {}" {
+		t.Errorf("Uploaded style.css = %q, %v", data, err)
+	}
+
+	back := filepath.Join(localRoot, "back")
+	if err := s.downloadTree(context.Background(), path.Join(remoteRoot, "site"), back); err != nil {
+		t.Fatalf("downloadTree failed: %v", err)
+	}
+	for _, name := range []string{"index.html", "css/style.css", "img/.gitkeep"} {
+		if _, err := os.Stat(filepath.Join(back, filepath.FromSlash(name))); err != nil {
+			t.Errorf("Downloaded tree is missing %s: %v", name, err)
+		}
+	}
+
+	paths := []string{filepath.Join(localRoot, "site"), filepath.Join(localRoot, "missing")}
+	if size, err := localTreeSize(context.Background(), paths); err != nil || size != 13 {
+		t.Errorf("localTreeSize = %d, %v, want 13", size, err)
+	}
+	cancelled, cancel := context.WithCancel(context.Background())
+	cancel()
+	if _, err := localTreeSize(cancelled, paths); !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled from localTreeSize, got %v", err)
+	}
+}
@@ -0,0 +1,444 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"path"
+	"path/filepath"
+	"strings"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+	"github.com/fsnotify/fsnotify"
+)
+
+// errRemoteChanged is returned when a remote file was modified by someone
+// else since it was downloaded for editing
+var errRemoteChanged = errors.New("remote file changed since download")
+
+// editSaveDelay groups the bursts of writes editors make when saving
+const editSaveDelay = 500 * time.Millisecond
+
+// editSession is a remote file downloaded to a local copy for editing
+type editSession struct {
+	remotePath string
+	localPath  string
+	dir        string
+
+	// Remote state after the last download or upload, used to detect
+	// changes made by others
+	remoteModTime time.Time
+	remoteSize    int64
+
+	status string
+	timer  *time.Timer
+	// unsaved is set while the local copy has changes not uploaded yet
+	unsaved bool
+}
+
+// editManager keeps the edit sessions, watches their local copies and
+// reports saves so they can be uploaded
+type editManager struct {
+	client  *SFTPGUIClient
+	baseDir string
+
+	mu       sync.Mutex
+	sessions []*editSession
+	watcher  *fsnotify.Watcher
+
+	// onChange is called when a session is added, removed or changes status
+	onChange func()
+	// onSaved is called when the local copy of a session was saved
+	onSaved func(s *editSession)
+}
+
+func newEditManager(client *SFTPGUIClient, baseDir string) *editManager {
+	return &editManager{client: client, baseDir: baseDir}
+}
+
+// start downloads remotePath into a new session directory and begins
+// watching it. An existing session for remotePath is returned as is. The
+// download stops once ctx is done.
+func (m *editManager) start(ctx context.Context, remotePath string) (*editSession, bool, error) {
+	if s := m.find(remotePath); s != nil {
+		return s, false, nil
+	}
+
+	info, err := m.client.sftpClient.Stat(remotePath)
+	if err != nil {
+		return nil, false, err
+	}
+	if info.IsDir() {
+		return nil, false, fmt.Errorf("%s is a directory", remotePath)
+	}
+
+	if err := os.MkdirAll(m.baseDir, 0700); err != nil {
+		return nil, false, err
+	}
+	dir, err := os.MkdirTemp(m.baseDir, "edit-")
+	if err != nil {
+		return nil, false, err
+	}
+
+	s := &editSession{
+		remotePath: remotePath,
+		// Keep the name so editors recognize the file type
+		localPath: filepath.Join(dir, path.Base(remotePath)),
+		dir:       dir,
+		status:    "Editing",
+	}
+	if err := m.download(ctx, s); err != nil {
+		os.RemoveAll(dir)
+		return nil, false, err
+	}
+	if err := m.watch(dir); err != nil {
+		os.RemoveAll(dir)
+		return nil, false, err
+	}
+
+	m.mu.Lock()
+	m.sessions = append(m.sessions, s)
+	m.mu.Unlock()
+	m.changed()
+	return s, true, nil
+}
+
+func (m *editManager) download(ctx context.Context, s *editSession) error {
+	if err := m.client.DownloadFileContext(ctx, s.remotePath, s.localPath); err != nil {
+		return err
+	}
+	return m.recordRemote(s)
+}
+
+// recordRemote remembers the current remote state of a session
+func (m *editManager) recordRemote(s *editSession) error {
+	info, err := m.client.sftpClient.Stat(s.remotePath)
+	if err != nil {
+		return err
+	}
+	m.mu.Lock()
+	s.remoteModTime, s.remoteSize = info.ModTime(), info.Size()
+	m.mu.Unlock()
+	return nil
+}
+
+// upload copies the local copy of a session back to the server. Unless
+// force is set it fails with errRemoteChanged when the remote file was
+// modified since the last download or upload.
+func (m *editManager) upload(s *editSession, force bool) error {
+	if !force {
+		info, err := m.client.sftpClient.Stat(s.remotePath)
+		if err != nil && !errors.Is(err, os.ErrNotExist) {
+			return err
+		}
+		m.mu.Lock()
+		changed := err != nil || !info.ModTime().Equal(s.remoteModTime) || info.Size() != s.remoteSize
+		m.mu.Unlock()
+		if changed {
+			return errRemoteChanged
+		}
+	}
+
+	localFile, err := os.Open(s.localPath)
+	if err != nil {
+		return err
+	}
+	defer localFile.Close()
+
+	remoteFile, err := m.client.sftpClient.Create(s.remotePath)
+	if err != nil {
+		return err
+	}
+	if _, err := io.Copy(remoteFile, localFile); err != nil {
+		remoteFile.Close()
+		return err
+	}
+	if err := remoteFile.Close(); err != nil {
+		return err
+	}
+	m.mu.Lock()
+	s.unsaved = false
+	m.mu.Unlock()
+	return m.recordRemote(s)
+}
+
+// watch adds dir to the watcher, creating the watcher on first use
+func (m *editManager) watch(dir string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.watcher == nil {
+		watcher, err := fsnotify.NewWatcher()
+		if err != nil {
+			return fmt.Errorf("cannot watch for changes: %v", err)
+		}
+		m.watcher = watcher
+		go m.run(watcher)
+	}
+	// Watch the directory rather than the file since many editors save
+	// by writing a new file and renaming it over the old one
+	return m.watcher.Add(dir)
+}
+
+// run dispatches watcher events until the watcher is closed
+func (m *editManager) run(watcher *fsnotify.Watcher) {
+	for {
+		select {
+		case event, ok := <-watcher.Events:
+			if !ok {
+				return
+			}
+			if !event.Has(fsnotify.Write) && !event.Has(fsnotify.Create) {
+				continue
+			}
+			m.mu.Lock()
+			for _, s := range m.sessions {
+				if s.localPath == event.Name {
+					m.scheduleSave(s)
+				}
+			}
+			m.mu.Unlock()
+		case _, ok := <-watcher.Errors:
+			if !ok {
+				return
+			}
+		}
+	}
+}
+
+// scheduleSave reports a save once the writes have settled. m.mu must be held.
+func (m *editManager) scheduleSave(s *editSession) {
+	s.unsaved = true
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+	s.timer = time.AfterFunc(editSaveDelay, func() {
+		if m.onSaved != nil {
+			m.onSaved(s)
+		}
+	})
+}
+
+// setStatus updates the status shown for a session
+func (m *editManager) setStatus(s *editSession, status string) {
+	m.mu.Lock()
+	s.status = status
+	m.mu.Unlock()
+	m.changed()
+}
+
+// find returns the session editing remotePath, if any
+func (m *editManager) find(remotePath string) *editSession {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	for _, s := range m.sessions {
+		if s.remotePath == remotePath {
+			return s
+		}
+	}
+	return nil
+}
+
+// list returns the open sessions
+func (m *editManager) list() []*editSession {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	return append([]*editSession(nil), m.sessions...)
+}
+
+// close stops watching a session and deletes its local copy
+func (m *editManager) close(s *editSession) {
+	m.remove(s)
+	os.RemoveAll(s.dir)
+}
+
+// remove stops watching a session and drops it from the list, leaving its
+// local copy in place
+func (m *editManager) remove(s *editSession) {
+	m.mu.Lock()
+	for i, other := range m.sessions {
+		if other == s {
+			m.sessions = append(m.sessions[:i], m.sessions[i+1:]...)
+			break
+		}
+	}
+	if s.timer != nil {
+		s.timer.Stop()
+	}
+	if m.watcher != nil {
+		m.watcher.Remove(s.dir)
+	}
+	m.mu.Unlock()
+	m.changed()
+}
+
+// closeAll closes every session. Local copies with changes that were not
+// uploaded are kept so no edit is lost; their paths are returned.
+func (m *editManager) closeAll() (kept []string) {
+	for _, s := range m.list() {
+		m.mu.Lock()
+		unsaved := s.unsaved
+		m.mu.Unlock()
+		if unsaved {
+			m.remove(s)
+			kept = append(kept, s.localPath)
+			continue
+		}
+		m.close(s)
+	}
+	return kept
+}
+
+func (m *editManager) changed() {
+	if m.onChange != nil {
+		m.onChange()
+	}
+}
+
+// onEdit opens the selected remote file for editing
+func (s *session) onEdit() {
+	if !s.client.IsConnected() {
+		return
+	}
+
+	entries := s.remotePane.selectedEntries()
+	if len(entries) != 1 || entries[0].IsDir || entries[0].LinkDir {
+		s.showError("Please select a single remote file to edit")
+		return
+	}
+
+	remotePath := path.Join(s.currentRemote, entries[0].Name)
+	s.showProgress(fmt.Sprintf("Downloading %s for editing...", remotePath))
+	ctx, done := s.startOperation()
+	go func() {
+		defer done()
+		edit, created, err := s.edits.start(ctx, remotePath)
+		s.hideProgress()
+		if ctx.Err() != nil {
+			s.logMessage(fmt.Sprintf("Editing %s cancelled", remotePath))
+			return
+		}
+		if err != nil {
+			s.showError(fmt.Sprintf("Edit failed: %v", err))
+			return
+		}
+		if created {
+			s.logMessage(fmt.Sprintf("Editing %s, saves are uploaded automatically", remotePath))
+		}
+		if err := s.openInEditor(edit.localPath); err != nil {
+			s.showError(fmt.Sprintf("Failed to open editor: %v", err))
+		}
+	}()
+}
+
+// openInEditor opens a local file with the configured editor command, or
+// the system default application when none is set
+func (app *SFTPApp) openInEditor(localPath string) error {
+	args := strings.Fields(app.settings.Editor)
+	if len(args) == 0 {
+		return app.openWithSystemDefault(localPath)
+	}
+
+	cmd := exec.Command(args[0], append(args[1:], localPath)...)
+	if err := cmd.Start(); err != nil {
+		return err
+	}
+	// Reap the editor process when it exits
+	go cmd.Wait()
+	return nil
+}
+
+// onEditSaved uploads a saved edit session, asking first when the remote
+// file was changed by someone else
+func (s *session) onEditSaved(edit *editSession) {
+	if !s.client.IsConnected() {
+		s.edits.setStatus(edit, "Not connected")
+		return
+	}
+
+	s.edits.setStatus(edit, "Uploading...")
+	err := s.edits.upload(edit, false)
+	if errors.Is(err, errRemoteChanged) {
+		s.edits.setStatus(edit, "Conflict")
+		s.do(func() {
+			dialog.ShowConfirm("Remote File Changed",
+				fmt.Sprintf("%s was modified on the server since it was opened for editing.\nOverwrite it with your version?", edit.remotePath),
+				func(overwrite bool) {
+					if !overwrite {
+						s.logMessage(fmt.Sprintf("Kept the remote version of %s", edit.remotePath))
+						return
+					}
+					// Upload in the background like a normal save
+					s.edits.setStatus(edit, "Uploading...")
+					go func() {
+						s.finishEditUpload(edit, s.edits.upload(edit, true))
+					}()
+				}, s.window)
+		})
+		return
+	}
+	s.finishEditUpload(edit, err)
+}
+
+func (s *session) finishEditUpload(edit *editSession, err error) {
+	if err != nil {
+		s.edits.setStatus(edit, "Upload failed")
+		s.showError(fmt.Sprintf("Upload of %s failed: %v", edit.remotePath, err))
+		return
+	}
+	s.edits.setStatus(edit, "Saved "+time.Now().Format("15:04:05"))
+	s.logMessage(fmt.Sprintf("Uploaded edited file: %s", edit.remotePath))
+	s.remoteChanged(edit.remotePath)
+	s.do(func() {
+		if path.Dir(edit.remotePath) == s.currentRemote {
+			s.updateRemoteFiles()
+		}
+	})
+}
+
+// createEditSessionsPanel creates the list of files open for editing in
+// the current session
+func (app *SFTPApp) createEditSessionsPanel() fyne.CanvasObject {
+	app.editRows = container.NewVBox()
+	return widget.NewCard("Edit Sessions", "", app.editRows)
+}
+
+// showEditSessions fills the edit sessions panel for the current session
+func (app *SFTPApp) showEditSessions() {
+	s := app.current
+	edits := s.edits.list()
+	objects := []fyne.CanvasObject{}
+	if len(edits) == 0 {
+		objects = append(objects, widget.NewLabel("No files being edited"))
+	}
+	for _, edit := range edits {
+		edit := edit
+		s.edits.mu.Lock()
+		status := edit.status
+		s.edits.mu.Unlock()
+
+		label := widget.NewLabel(fmt.Sprintf("%s\n%s", path.Base(edit.remotePath), status))
+		label.Truncation = fyne.TextTruncateEllipsis
+		openBtn := widget.NewButtonWithIcon("", theme.DocumentCreateIcon(), func() {
+			if err := s.openInEditor(edit.localPath); err != nil {
+				s.showError(fmt.Sprintf("Failed to open editor: %v", err))
+			}
+		})
+		closeBtn := widget.NewButtonWithIcon("", theme.CancelIcon(), func() {
+			s.edits.close(edit)
+			s.logMessage(fmt.Sprintf("Closed edit session for %s", edit.remotePath))
+		})
+		objects = append(objects, container.NewBorder(nil, nil, nil, container.NewHBox(openBtn, closeBtn), label))
+	}
+	app.editRows.Objects = objects
+	app.editRows.Refresh()
+}
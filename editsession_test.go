@@ -0,0 +1,96 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestEditManager(t *testing.T) {
+	client, root := newTestClient(t)
+	writeTestFiles(t, root, map[string]string{"conf/app.ini": "a=1\n"})
+
+	m := newEditManager(client, t.TempDir())
+	saved := make(chan *editSession, 1)
+	m.onSaved = func(s *editSession) { saved <- s }
+	defer m.closeAll()
+
+	s, created, err := m.start(context.Background(), root+"/conf/app.ini")
+	if err != nil || !created {
+		t.Fatalf("start failed: %v", err)
+	}
+	if filepath.Base(s.localPath) != "app.ini" {
+		t.Errorf("Expected the local copy to keep its name, got %s", s.localPath)
+	}
+	if data, _ := os.ReadFile(s.localPath); string(data) != "a=1\n" {
+		t.Errorf("Unexpected local copy %q", data)
+	}
+	if again, created, _ := m.start(context.Background(), root+"/conf/app.ini"); again != s || created {
+		t.Error("Expected the existing session to be reused")
+	}
+
+	// Saving the local copy is reported and can be uploaded
+	if err := os.WriteFile(s.localPath, []byte("a=2\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case got := <-saved:
+		if got != s {
+			t.Fatal("Save reported for the wrong session")
+		}
+	case <-time.After(5 * time.Second):
+		t.Fatal("Save was not reported")
+	}
+	if err := m.upload(s, false); err != nil {
+		t.Fatalf("upload failed: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(root, "conf", "app.ini")); string(data) != "a=2\n" {
+		t.Errorf("Unexpected remote content %q", data)
+	}
+
+	// A change made by someone else is a conflict unless forced
+	writeTestFiles(t, root, map[string]string{"conf/app.ini": "a=3, changed remotely\n"})
+	if err := m.upload(s, false); !errors.Is(err, errRemoteChanged) {
+		t.Fatalf("Expected errRemoteChanged, got %v", err)
+	}
+	if err := m.upload(s, true); err != nil {
+		t.Fatalf("Forced upload failed: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(root, "conf", "app.ini")); string(data) != "a=2\n" {
+		t.Errorf("Unexpected remote content after forced upload %q", data)
+	}
+
+	m.close(s)
+	if len(m.list()) != 0 {
+		t.Error("Expected no sessions after close")
+	}
+	if _, err := os.Stat(s.dir); !os.IsNotExist(err) {
+		t.Error("Expected the local copy to be deleted")
+	}
+
+	// Closing all sessions keeps copies whose changes were not uploaded
+	s, _, err = m.start(context.Background(), root+"/conf/app.ini")
+	if err != nil {
+		t.Fatalf("start failed: %v", err)
+	}
+	if err := os.WriteFile(s.localPath, []byte("a=4\n"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	select {
+	case <-saved:
+	case <-time.After(5 * time.Second):
+		t.Fatal("Save was not reported")
+	}
+	if kept := m.closeAll(); len(kept) != 1 || kept[0] != s.localPath {
+		t.Errorf("Expected %s to be kept, got %v", s.localPath, kept)
+	}
+	if data, _ := os.ReadFile(s.localPath); string(data) != "a=4\n" {
+		t.Errorf("Expected the unsaved edits to be kept, got %q", data)
+	}
+	if len(m.list()) != 0 {
+		t.Error("Expected no sessions after closeAll")
+	}
+}
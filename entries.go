@@ -0,0 +1,184 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/sftp"
+)
+
+// fileEntry describes a single entry of a local or remote directory listing
+type fileEntry struct {
+	Name       string
+	Size       int64
+	Mode       os.FileMode
+	ModTime    time.Time
+	Owner      string
+	LinkTarget string
+	LinkBroken bool // the link target does not exist
+	LinkDir    bool // the link target is a directory
+	IsDir      bool
+	Hidden     bool
+	Stale      bool // changed on the server since it was listed
+}
+
+// parentDirEntry is the entry used to go up one directory
+var parentDirEntry = fileEntry{Name: "..", IsDir: true, Mode: os.ModeDir}
+
+// IsParent reports whether the entry is the ".." entry
+func (e fileEntry) IsParent() bool {
+	return e.Name == ".."
+}
+
+// IsSymlink reports whether the entry is a symbolic link
+func (e fileEntry) IsSymlink() bool {
+	return e.Mode&os.ModeSymlink != 0
+}
+
+// DisplayName returns the name prefixed with an icon for its type.
+// Symlinks show their target, and broken links and stale entries are
+// flagged.
+func (e fileEntry) DisplayName() string {
+	if e.Stale {
+		return e.displayName() + " (changed on server)"
+	}
+	return e.displayName()
+}
+
+func (e fileEntry) displayName() string {
+	prefix := "📄 "
+	if e.IsDir || e.LinkDir {
+		prefix = "📁 "
+	}
+	if !e.IsSymlink() {
+		return prefix + e.Name
+	}
+
+	name := "🔗 " + e.Name
+	if e.LinkTarget != "" {
+		name += " → " + e.LinkTarget
+	}
+	if e.LinkBroken {
+		return "⚠️ " + name + " (broken)"
+	}
+	return prefix + name
+}
+
+// setLink records the target of a symlink entry and the result of
+// resolving it
+func (e *fileEntry) setLink(target string, resolved os.FileInfo, err error) {
+	e.LinkTarget = target
+	e.LinkBroken = err != nil
+	e.LinkDir = err == nil && resolved.IsDir()
+}
+
+// SizeText returns the human-readable size, empty for directories
+func (e fileEntry) SizeText() string {
+	if e.IsDir || e.LinkDir {
+		return ""
+	}
+	return humanSize(e.Size)
+}
+
+// ModTimeText returns the modification time, empty when unknown
+func (e fileEntry) ModTimeText() string {
+	if e.ModTime.IsZero() {
+		return ""
+	}
+	return e.ModTime.Format("2006-01-02 15:04")
+}
+
+// newFileEntry converts a FileInfo from a local or remote listing
+func newFileEntry(info os.FileInfo) fileEntry {
+	entry := fileEntry{
+		Name:    info.Name(),
+		Size:    info.Size(),
+		Mode:    info.Mode(),
+		ModTime: info.ModTime(),
+		IsDir:   info.IsDir(),
+		Hidden:  strings.HasPrefix(info.Name(), "."),
+	}
+	if stat, ok := info.Sys().(*sftp.FileStat); ok {
+		entry.Owner = fmt.Sprintf("%d:%d", stat.UID, stat.GID)
+	} else {
+		entry.Owner = localOwner(info)
+	}
+	return entry
+}
+
+// humanSize formats a byte count using binary units
+func humanSize(size int64) string {
+	const unit = 1024
+	if size < unit {
+		return fmt.Sprintf("%d B", size)
+	}
+	div, exp := int64(unit), 0
+	for n := size / unit; n >= unit; n /= unit {
+		div *= unit
+		exp++
+	}
+	return fmt.Sprintf("%.1f %cB", float64(size)/float64(div), "KMGTPE"[exp])
+}
+
+// Sortable columns of the file tables
+const (
+	sortByName        = "name"
+	sortBySize        = "size"
+	sortByModified    = "modified"
+	sortByPermissions = "permissions"
+	sortByOwner       = "owner"
+)
+
+// sortSetting is the persisted sort choice of a pane
+type sortSetting struct {
+	Column    string `json:"column"`
+	Ascending bool   `json:"ascending"`
+}
+
+// defaultSort sorts by name, A to Z
+var defaultSort = sortSetting{Column: sortByName, Ascending: true}
+
+// sortEntries orders entries by the given setting. The ".." entry always
+// comes first and directories, including links to directories, are
+// always listed before files.
+func sortEntries(entries []fileEntry, setting sortSetting) {
+	less := func(a, b fileEntry) bool {
+		switch setting.Column {
+		case sortBySize:
+			if a.Size != b.Size {
+				return a.Size < b.Size
+			}
+		case sortByModified:
+			if !a.ModTime.Equal(b.ModTime) {
+				return a.ModTime.Before(b.ModTime)
+			}
+		case sortByPermissions:
+			if a.Mode.Perm() != b.Mode.Perm() {
+				return a.Mode.Perm() < b.Mode.Perm()
+			}
+		case sortByOwner:
+			if a.Owner != b.Owner {
+				return a.Owner < b.Owner
+			}
+		}
+		return strings.ToLower(a.Name) < strings.ToLower(b.Name)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if a.IsParent() != b.IsParent() {
+			return a.IsParent()
+		}
+		// Links to folders open like folders, so they sort with them
+		if aDir, bDir := a.IsDir || a.LinkDir, b.IsDir || b.LinkDir; aDir != bDir {
+			return aDir
+		}
+		if setting.Ascending {
+			return less(a, b)
+		}
+		return less(b, a)
+	})
+}
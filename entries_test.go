@@ -0,0 +1,59 @@
+package main
+
+import (
+	"os"
+	"testing"
+	"time"
+)
+
+func TestHumanSize(t *testing.T) {
+	tests := map[int64]string{
+		0:               "0 B",
+		1023:            "1023 B",
+		1024:            "1.0 KB",
+		1536:            "1.5 KB",
+		5 * 1024 * 1024: "5.0 MB",
+		3 << 30:         "3.0 GB",
+		1<<40 + 1<<39:   "1.5 TB",
+	}
+	for size, want := range tests {
+		if got := humanSize(size); got != want {
+			t.Errorf("humanSize(%d) = %q, want %q", size, got, want)
+		}
+	}
+}
+
+func TestSortEntries(t *testing.T) {
+	now := time.Now()
+	entries := []fileEntry{
+		{Name: "b.txt", Size: 10, ModTime: now},
+		{Name: "docs", IsDir: true, Mode: os.ModeDir},
+		{Name: "a.txt", Size: 30, ModTime: now.Add(-time.Hour)},
+		parentDirEntry,
+		{Name: "C.txt", Size: 20, ModTime: now.Add(time.Hour)},
+		{Name: "link", Mode: os.ModeSymlink, LinkDir: true},
+	}
+
+	names := func() []string {
+		var out []string
+		for _, e := range entries {
+			out = append(out, e.Name)
+		}
+		return out
+	}
+
+	sortEntries(entries, sortSetting{Column: sortByName, Ascending: true})
+	if got := names(); got[0] != ".." || got[1] != "docs" || got[2] != "link" || got[3] != "a.txt" || got[5] != "C.txt" {
+		t.Errorf("Name sort returned %v", got)
+	}
+
+	sortEntries(entries, sortSetting{Column: sortBySize, Ascending: false})
+	if got := names(); got[0] != ".." || got[1] != "link" || got[2] != "docs" || got[3] != "a.txt" || got[5] != "b.txt" {
+		t.Errorf("Descending size sort returned %v", got)
+	}
+
+	sortEntries(entries, sortSetting{Column: sortByModified, Ascending: true})
+	if got := names(); got[3] != "a.txt" || got[5] != "C.txt" {
+		t.Errorf("Modified sort returned %v", got)
+	}
+}
@@ -58,7 +58,7 @@ func main() {
 
 	// Example 2: List current directory
 	fmt.Println("\n=== Directory Listing ===")
-	err = client.ListDirectory(".")
+	err = client.ListDirectory(".", false)
 	if err != nil {
 		log.Printf("Failed to list directory: %v", err)
 	}
@@ -99,7 +99,7 @@ func main() {
 
 	// Example 5: List the test directory to verify upload
 	fmt.Println("\n=== Verifying Upload ===")
-	err = client.ListDirectory(testDir)
+	err = client.ListDirectory(testDir, false)
 	if err != nil {
 		log.Printf("Failed to list test directory: %v", err)
 	}
@@ -188,7 +188,7 @@ func exampleBatchOperations(client *SFTPClient) error {
 
 	// List the batch directory
 	fmt.Println("\nBatch directory contents:")
-	err = client.ListDirectory(remoteDir)
+	err = client.ListDirectory(remoteDir, false)
 	if err != nil {
 		log.Printf("Failed to list batch directory: %v", err)
 	}
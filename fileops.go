@@ -0,0 +1,314 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// errTargetExists is returned when a rename or move would replace an existing entry
+var errTargetExists = errors.New("target already exists")
+
+// errTargetIsDir is returned when a rename or move would replace a folder.
+// Folders are never replaced, since that would delete what they hold.
+var errTargetIsDir = errors.New("cannot replace a folder")
+
+// Exists reports whether a remote path exists, without following symlinks
+func (c *SFTPGUIClient) Exists(p string) (bool, error) {
+	if !c.IsConnected() {
+		return false, fmt.Errorf("not connected")
+	}
+
+	_, err := c.sftpClient.Lstat(p)
+	if err == nil {
+		return true, nil
+	}
+	if errors.Is(err, os.ErrNotExist) {
+		return false, nil
+	}
+	return false, err
+}
+
+// Rename moves oldPath to newPath. It uses the atomic
+// posix-rename@openssh.com extension when the server supports it and
+// falls back to a plain SFTP rename otherwise. An existing file at
+// newPath is only replaced when overwrite is set; a folder never is.
+func (c *SFTPGUIClient) Rename(oldPath, newPath string, overwrite bool) error {
+	if !c.IsConnected() {
+		return fmt.Errorf("not connected")
+	}
+
+	info, err := c.sftpClient.Lstat(newPath)
+	if err != nil && !errors.Is(err, os.ErrNotExist) {
+		return err
+	}
+	exists := err == nil
+	if exists && info.IsDir() {
+		return fmt.Errorf("%w: %s", errTargetIsDir, newPath)
+	}
+	if exists && !overwrite {
+		return fmt.Errorf("%w: %s", errTargetExists, newPath)
+	}
+
+	if _, ok := c.sftpClient.HasExtension("posix-rename@openssh.com"); ok {
+		return c.sftpClient.PosixRename(oldPath, newPath)
+	}
+
+	// A plain SFTP rename refuses to replace the target
+	if exists {
+		if err := c.sftpClient.Remove(newPath); err != nil {
+			return fmt.Errorf("failed to replace %s: %v", newPath, err)
+		}
+	}
+	return c.sftpClient.Rename(oldPath, newPath)
+}
+
+// renameLocal moves oldPath to newPath on the local file system. An
+// existing file at newPath is only replaced when overwrite is set; a
+// folder never is.
+func renameLocal(oldPath, newPath string, overwrite bool) error {
+	if info, err := os.Lstat(newPath); err == nil {
+		if info.IsDir() {
+			return fmt.Errorf("%w: %s", errTargetIsDir, newPath)
+		}
+		if !overwrite {
+			return fmt.Errorf("%w: %s", errTargetExists, newPath)
+		}
+		// os.Rename does not replace files on every platform
+		if err := os.Remove(newPath); err != nil {
+			return fmt.Errorf("failed to replace %s: %v", newPath, err)
+		}
+	}
+	return os.Rename(oldPath, newPath)
+}
+
+// conflictAction is the user's answer when targets already exist
+type conflictAction int
+
+const (
+	conflictCancel conflictAction = iota
+	conflictSkip
+	conflictOverwrite
+)
+
+// askConflict asks how to handle existing targets. Skip is only offered
+// when more than one entry is being processed.
+func (app *SFTPApp) askConflict(existing []string, total int, onChoice func(conflictAction)) {
+	message := fmt.Sprintf("'%s' already exists.", existing[0])
+	if len(existing) > 1 {
+		shown := existing
+		if len(shown) > 10 {
+			shown = append(shown[:10:10], "...")
+		}
+		message = fmt.Sprintf("%d items already exist:\n%s", len(existing), strings.Join(shown, "\n"))
+	}
+
+	var d *dialog.CustomDialog
+	choose := func(action conflictAction) func() {
+		return func() {
+			d.Hide()
+			onChoice(action)
+		}
+	}
+
+	buttons := []fyne.CanvasObject{widget.NewButton("Cancel", choose(conflictCancel))}
+	if total > 1 {
+		buttons = append(buttons, widget.NewButton("Skip Existing", choose(conflictSkip)))
+	}
+	overwriteBtn := widget.NewButton("Overwrite", choose(conflictOverwrite))
+	overwriteBtn.Importance = widget.DangerImportance
+	buttons = append(buttons, overwriteBtn)
+
+	d = dialog.NewCustomWithoutButtons("Replace Existing?", container.NewVBox(widget.NewLabel(message)), app.window)
+	d.SetButtons(buttons)
+	d.Show()
+}
+
+// onRename starts an inline rename in the active pane
+func (s *session) onRename() {
+	if s.activePane == s.remotePane && !s.client.IsConnected() {
+		return
+	}
+	if !s.activePane.startRename() {
+		s.showError("Please select a single entry to rename")
+	}
+}
+
+// renameRemote renames or moves a remote entry of the current directory
+// in the background, asking before it replaces an existing file. newName
+// may be a path relative to the current directory.
+func (s *session) renameRemote(entry fileEntry, newName string) {
+	remoteDir := s.currentRemote
+	oldPath := path.Join(remoteDir, entry.Name)
+	newPath := path.Join(remoteDir, newName)
+
+	var rename func(overwrite bool)
+	rename = func(overwrite bool) {
+		_, done := s.startOperation()
+		go func() {
+			defer done()
+			err := s.client.Rename(oldPath, newPath, overwrite)
+			switch {
+			case errors.Is(err, errTargetExists):
+				s.do(func() {
+					s.askConflict([]string{newName}, 1, func(action conflictAction) {
+						if action == conflictOverwrite {
+							rename(true)
+						}
+					})
+				})
+			case err != nil:
+				s.showError(fmt.Sprintf("Rename failed: %v", err))
+			default:
+				s.remoteChanged(oldPath, newPath)
+				s.logMessage(fmt.Sprintf("Renamed: %s → %s", entry.Name, newName))
+				s.do(s.updateRemoteFiles)
+			}
+		}()
+	}
+	rename(false)
+}
+
+// renameLocalEntry renames or moves a local entry of the current directory.
+// newName may be a path relative to the current directory.
+func (app *SFTPApp) renameLocalEntry(entry fileEntry, newName string) {
+	localDir := app.currentLocal
+	oldPath := filepath.Join(localDir, entry.Name)
+	newPath := filepath.Join(localDir, newName)
+
+	rename := func(overwrite bool) {
+		if err := renameLocal(oldPath, newPath, overwrite); err != nil {
+			app.showError(fmt.Sprintf("Rename failed: %v", err))
+			return
+		}
+		app.logMessage(fmt.Sprintf("Renamed: %s → %s", entry.Name, newName))
+		app.updateLocalFiles()
+	}
+
+	if info, err := os.Lstat(newPath); err != nil || info.IsDir() {
+		// A folder is refused by renameLocal without asking
+		rename(false)
+		return
+	}
+	app.askConflict([]string{newName}, 1, func(action conflictAction) {
+		if action == conflictOverwrite {
+			rename(true)
+		}
+	})
+}
+
+// moveEntries moves entries of the pane's current directory into the
+// subdirectory dirName, asking first if any of them already exist there.
+// The existing entries are looked up in the background.
+func (s *session) moveEntries(pane *filePane, entries []fileEntry, dirName string) {
+	var names []string
+	for _, entry := range entries {
+		if entry.Name == dirName {
+			// Never move a folder into itself
+			continue
+		}
+		names = append(names, entry.Name)
+	}
+	if len(names) == 0 {
+		return
+	}
+
+	remote := pane == s.remotePane
+	var join func(elem ...string) string
+	var exists func(string) bool
+	var rename func(oldPath, newPath string, overwrite bool) error
+	var refresh func()
+	var baseDir string
+	if remote {
+		baseDir, join, refresh = s.currentRemote, path.Join, s.updateRemoteFiles
+		exists = func(p string) bool { ok, _ := s.client.Exists(p); return ok }
+		rename = func(oldPath, newPath string, overwrite bool) error {
+			defer s.remoteChanged(oldPath, newPath)
+			return s.client.Rename(oldPath, newPath, overwrite)
+		}
+	} else {
+		baseDir, join, refresh = s.currentLocal, filepath.Join, s.updateLocalFiles
+		exists = func(p string) bool { _, err := os.Lstat(p); return err == nil }
+		rename = renameLocal
+	}
+	destDir := join(baseDir, dirName)
+
+	run := func(action conflictAction, existing []string) {
+		skip := make(map[string]bool)
+		if action == conflictSkip {
+			for _, name := range existing {
+				skip[name] = true
+			}
+		}
+		var todo []string
+		for _, name := range names {
+			if !skip[name] {
+				todo = append(todo, name)
+			}
+		}
+		if len(todo) == 0 {
+			return
+		}
+		s.runBatch("Move", "Moved", todo, func(_ context.Context, name string) error {
+			return rename(join(baseDir, name), join(destDir, name), action == conflictOverwrite)
+		}, refresh)
+	}
+
+	s.showProgress(fmt.Sprintf("Checking %d item(s) to move...", len(names)))
+	ctx, done := s.startOperation()
+	go func() {
+		defer done()
+		var existing []string
+		for _, name := range names {
+			if ctx.Err() != nil {
+				break
+			}
+			if exists(join(destDir, name)) {
+				existing = append(existing, name)
+			}
+		}
+		s.hideProgress()
+		if ctx.Err() != nil {
+			s.logMessage("Move cancelled")
+			return
+		}
+
+		s.do(func() {
+			if len(existing) == 0 {
+				run(conflictSkip, nil)
+				return
+			}
+			s.askConflict(existing, len(names), func(action conflictAction) {
+				if action != conflictCancel {
+					run(action, existing)
+				}
+			})
+		})
+	}()
+}
+
+// onEntriesDropped handles entries dragged from src and dropped on dst.
+// Dropping onto a folder of the same pane moves the entries into it and
+// dropping onto the other pane transfers them.
+func (s *session) onEntriesDropped(src *filePane, entries []fileEntry, dst *filePane, target *fileEntry) {
+	if src != dst {
+		s.transferDropped(src, entries, dst, target)
+		return
+	}
+	if target == nil || !(target.IsDir || target.LinkDir) {
+		return
+	}
+	if src == s.remotePane && !s.client.IsConnected() {
+		return
+	}
+	s.moveEntries(src, entries, target.Name)
+}
@@ -0,0 +1,72 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSFTPGUIClient_Rename(t *testing.T) {
+	client, root := newTestClient(t)
+	writeTestFiles(t, root, map[string]string{
+		"a.txt": "a",
+		"b.txt": "b",
+	})
+
+	err := client.Rename(root+"/a.txt", root+"/b.txt", false)
+	if !errors.Is(err, errTargetExists) {
+		t.Fatalf("Expected errTargetExists, got %v", err)
+	}
+
+	if err := client.Rename(root+"/a.txt", root+"/b.txt", true); err != nil {
+		t.Fatalf("Rename with overwrite failed: %v", err)
+	}
+	data, err := os.ReadFile(filepath.Join(root, "b.txt"))
+	if err != nil || string(data) != "a" {
+		t.Errorf("Expected b.txt to hold the renamed content, got %q, %v", data, err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "a.txt")); !os.IsNotExist(err) {
+		t.Error("a.txt should no longer exist")
+	}
+
+	writeTestFiles(t, root, map[string]string{"dir/c.txt": "c"})
+	if err := client.Rename(root+"/b.txt", root+"/dir", true); !errors.Is(err, errTargetIsDir) {
+		t.Errorf("Expected errTargetIsDir, got %v", err)
+	}
+}
+
+func TestRenameLocal(t *testing.T) {
+	root := t.TempDir()
+	writeTestFiles(t, root, map[string]string{
+		"a.txt":       "a",
+		"dir/b.txt":   "b",
+		"dir/a.txt":   "old",
+		"other/c.txt": "c",
+	})
+
+	err := renameLocal(filepath.Join(root, "a.txt"), filepath.Join(root, "dir", "a.txt"), false)
+	if !errors.Is(err, errTargetExists) {
+		t.Fatalf("Expected errTargetExists, got %v", err)
+	}
+
+	if err := renameLocal(filepath.Join(root, "a.txt"), filepath.Join(root, "dir", "a.txt"), true); err != nil {
+		t.Fatalf("renameLocal with overwrite failed: %v", err)
+	}
+	if data, _ := os.ReadFile(filepath.Join(root, "dir", "a.txt")); string(data) != "a" {
+		t.Errorf("Expected moved content, got %q", data)
+	}
+
+	if err := renameLocal(filepath.Join(root, "other"), filepath.Join(root, "moved"), false); err != nil {
+		t.Fatalf("Renaming a directory failed: %v", err)
+	}
+
+	// A folder is never replaced, even with overwrite
+	err = renameLocal(filepath.Join(root, "moved"), filepath.Join(root, "dir"), true)
+	if !errors.Is(err, errTargetIsDir) {
+		t.Errorf("Expected errTargetIsDir, got %v", err)
+	}
+	if _, err := os.Stat(filepath.Join(root, "dir", "b.txt")); err != nil {
+		t.Errorf("The folder should be left alone: %v", err)
+	}
+}
@@ -0,0 +1,99 @@
+package main
+
+import (
+	"fmt"
+	"path/filepath"
+	"regexp"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Name matching modes for the quick filter and the remote search
+const (
+	matchSubstring = "Contains"
+	matchGlob      = "Glob"
+	matchRegex     = "Regex"
+)
+
+var matchModes = []string{matchSubstring, matchGlob, matchRegex}
+
+// newNameMatcher returns a function reporting whether a file name matches
+// pattern in the given mode. Substring and glob matching ignore case. An
+// empty pattern matches everything.
+func newNameMatcher(mode, pattern string) (func(name string) bool, error) {
+	if pattern == "" {
+		return func(string) bool { return true }, nil
+	}
+
+	switch mode {
+	case matchGlob:
+		pattern = strings.ToLower(pattern)
+		if _, err := filepath.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("invalid glob pattern: %v", err)
+		}
+		return func(name string) bool {
+			ok, _ := filepath.Match(pattern, strings.ToLower(name))
+			return ok
+		}, nil
+	case matchRegex:
+		re, err := regexp.Compile(pattern)
+		if err != nil {
+			return nil, fmt.Errorf("invalid regular expression: %v", err)
+		}
+		return re.MatchString, nil
+	default:
+		pattern = strings.ToLower(pattern)
+		return func(name string) bool {
+			return strings.Contains(strings.ToLower(name), pattern)
+		}, nil
+	}
+}
+
+// filterBar holds the quick filter controls of a pane
+type filterBar struct {
+	entry *widget.Entry
+	mode  *widget.Select
+}
+
+// newFilterBar creates the filter entry and mode selector. onChange is
+// called with a new matcher whenever the pattern or the mode changes.
+func newFilterBar(onChange func(match func(string) bool)) *filterBar {
+	bar := &filterBar{
+		entry: widget.NewEntry(),
+	}
+	bar.entry.SetPlaceHolder("Filter")
+
+	update := func() {
+		match, err := newNameMatcher(bar.mode.Selected, bar.entry.Text)
+		if err != nil {
+			// Keep the previous filter until the pattern is valid again
+			return
+		}
+		onChange(match)
+	}
+	bar.entry.Validator = func(text string) error {
+		_, err := newNameMatcher(bar.mode.Selected, text)
+		return err
+	}
+	bar.entry.OnChanged = func(string) { update() }
+	bar.mode = widget.NewSelect(matchModes, func(string) {
+		bar.entry.Validate()
+		update()
+	})
+	bar.mode.SetSelected(matchSubstring)
+
+	return bar
+}
+
+// clear resets the filter pattern
+func (b *filterBar) clear() {
+	b.entry.SetText("")
+}
+
+// content returns the filter controls laid out in a row
+func (b *filterBar) content() fyne.CanvasObject {
+	return container.NewBorder(nil, nil, nil, b.mode, b.entry)
+}
@@ -0,0 +1,38 @@
+package main
+
+import (
+	"testing"
+)
+
+func TestNewNameMatcher(t *testing.T) {
+	tests := []struct {
+		mode, pattern, name string
+		want                bool
+	}{
+		{matchSubstring, "", "anything", true},
+		{matchSubstring, "LOG", "server.log", true},
+		{matchSubstring, "log", "server.txt", false},
+		{matchGlob, "*.LOG", "server.log", true},
+		{matchGlob, "*.log", "server.log.1", false},
+		{matchGlob, "server-??.log", "server-01.log", true},
+		{matchRegex, `^access-\d+\.log$`, "access-42.log", true},
+		{matchRegex, `^access-\d+\.log$`, "error-42.log", false},
+	}
+
+	for _, tt := range tests {
+		match, err := newNameMatcher(tt.mode, tt.pattern)
+		if err != nil {
+			t.Fatalf("newNameMatcher(%s, %q) failed: %v", tt.mode, tt.pattern, err)
+		}
+		if got := match(tt.name); got != tt.want {
+			t.Errorf("%s %q on %q = %v, want %v", tt.mode, tt.pattern, tt.name, got, tt.want)
+		}
+	}
+
+	if _, err := newNameMatcher(matchRegex, "("); err == nil {
+		t.Error("Invalid regular expression should fail")
+	}
+	if _, err := newNameMatcher(matchGlob, "["); err == nil {
+		t.Error("Invalid glob pattern should fail")
+	}
+}
@@ -233,7 +233,7 @@ if ($compilerFound) {
     try {
         # Try standard build first
         Write-Info "Attempting standard GUI build..."
-        go build -ldflags "-s -w" -o sftp-client-gui.exe main.go app_icon.go
+        go build -ldflags "-s -w" -o sftp-client-gui.exe .
 
         if ($LASTEXITCODE -eq 0 -and (Test-Path "sftp-client-gui.exe")) {
             $guiSize = [math]::Round((Get-Item "sftp-client-gui.exe").Length / 1MB, 2)
@@ -265,7 +265,7 @@ if ($compilerFound) {
         # Try static build if standard build failed
         if (-not $guiWorks) {
             Write-Info "Attempting static GUI build..."
-            go build -ldflags "-s -w -extldflags=-static" -o sftp-client-gui-static.exe main.go app_icon.go
+            go build -ldflags "-s -w -extldflags=-static" -o sftp-client-gui-static.exe .
 
             if ($LASTEXITCODE -eq 0 -and (Test-Path "sftp-client-gui-static.exe")) {
                 $staticSize = [math]::Round((Get-Item "sftp-client-gui-static.exe").Length / 1MB, 2)
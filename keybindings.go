@@ -0,0 +1,229 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/driver/desktop"
+)
+
+// defaultKeybindings maps the keyboard actions to their keys. Ctrl stands
+// for the platform's shortcut modifier, which is Cmd on macOS.
+var defaultKeybindings = map[string]string{
+	"switch_pane":   "Tab",
+	"open":          "Return",
+	"go_up":         "BackSpace",
+	"refresh":       "F5",
+	"rename":        "F2",
+	"delete":        "Delete",
+	"upload":        "Ctrl+U",
+	"download":      "Ctrl+D",
+	"focus_path":    "Ctrl+L",
+	"new_session":   "Ctrl+N",
+	"toggle_hidden": "Ctrl+H",
+}
+
+// keyBinding is a key with its modifiers, written like "Ctrl+Shift+U"
+type keyBinding struct {
+	key      fyne.KeyName
+	modifier fyne.KeyModifier
+}
+
+// keyModifiers are the modifier names accepted in keybindings
+var keyModifiers = map[string]fyne.KeyModifier{
+	"ctrl":  fyne.KeyModifierShortcutDefault,
+	"shift": fyne.KeyModifierShift,
+	"alt":   fyne.KeyModifierAlt,
+	"super": fyne.KeyModifierSuper,
+}
+
+// parseKeyBinding parses a key such as "F5" or "Ctrl+Shift+U". Key names
+// are those of Fyne, for example Return, BackSpace, Delete or Tab.
+func parseKeyBinding(text string) (keyBinding, error) {
+	parts := strings.Split(text, "+")
+	var binding keyBinding
+	for _, part := range parts[:len(parts)-1] {
+		modifier, ok := keyModifiers[strings.ToLower(strings.TrimSpace(part))]
+		if !ok {
+			return keyBinding{}, fmt.Errorf("unknown modifier %q in %q", part, text)
+		}
+		binding.modifier |= modifier
+	}
+
+	key := strings.TrimSpace(parts[len(parts)-1])
+	if key == "" {
+		return keyBinding{}, fmt.Errorf("missing key in %q", text)
+	}
+	if len(key) == 1 {
+		key = strings.ToUpper(key)
+	}
+	binding.key = fyne.KeyName(key)
+	return binding, nil
+}
+
+// loadKeybindings reads the keybindings file, writing the defaults to it
+// when it doesn't exist yet. Actions missing from the file keep their
+// default key and an empty key disables an action. Invalid entries are
+// reported and keep their default.
+func loadKeybindings(file string) (map[string]keyBinding, []error) {
+	keys := make(map[string]string, len(defaultKeybindings))
+	for action, key := range defaultKeybindings {
+		keys[action] = key
+	}
+
+	var errs []error
+	data, err := os.ReadFile(file)
+	if os.IsNotExist(err) {
+		if data, err := json.MarshalIndent(defaultKeybindings, "", "  "); err == nil {
+			os.WriteFile(file, data, 0600)
+		}
+	} else if err != nil {
+		errs = append(errs, err)
+	} else {
+		var custom map[string]string
+		if err := json.Unmarshal(data, &custom); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", filepath.Base(file), err))
+		}
+		for action, key := range custom {
+			if _, ok := defaultKeybindings[action]; !ok {
+				errs = append(errs, fmt.Errorf("unknown action %q", action))
+				continue
+			}
+			keys[action] = key
+		}
+	}
+
+	bindings := make(map[string]keyBinding, len(keys))
+	for action, key := range keys {
+		if key == "" {
+			continue
+		}
+		binding, err := parseKeyBinding(key)
+		if err != nil {
+			errs = append(errs, fmt.Errorf("%s: %v", action, err))
+			binding, _ = parseKeyBinding(defaultKeybindings[action])
+		}
+		bindings[action] = binding
+	}
+	return bindings, errs
+}
+
+// keyActions returns the handlers of the keyboard actions
+func (app *SFTPApp) keyActions() map[string]func() {
+	connected := func(op func(*session)) func() {
+		return func() {
+			if app.current.client.IsConnected() {
+				op(app.current)
+			}
+		}
+	}
+	return map[string]func(){
+		"switch_pane":   app.switchPane,
+		"open":          app.openSelected,
+		"go_up":         app.goUp,
+		"refresh":       app.inSession((*session).onRefresh),
+		"rename":        app.inSession((*session).onRename),
+		"delete":        app.inSession((*session).onDelete),
+		"upload":        connected((*session).onUpload),
+		"download":      connected((*session).onDownload),
+		"focus_path":    app.focusPath,
+		"new_session":   app.showNewSession,
+		"toggle_hidden": func() { app.activePane.toggleHidden() },
+	}
+}
+
+// setupShortcuts registers the window-wide keyboard shortcuts from the
+// keybindings file. Keys with modifiers become canvas shortcuts, plain
+// keys are handled while a file pane or nothing has focus.
+func (app *SFTPApp) setupShortcuts() {
+	bindings, errs := loadKeybindings(app.keybindingsFile)
+	for _, err := range errs {
+		app.logMessage(fmt.Sprintf("Keybindings: %v", err))
+	}
+
+	actions := app.keyActions()
+	names := make([]string, 0, len(bindings))
+	for action := range bindings {
+		names = append(names, action)
+	}
+	// Register in a stable order so a key bound twice always runs the same action
+	sort.Strings(names)
+
+	app.plainKeys = make(map[fyne.KeyName]func())
+	for _, action := range names {
+		binding, run := bindings[action], actions[action]
+		if binding.modifier == 0 {
+			app.plainKeys[binding.key] = run
+			continue
+		}
+		app.window.Canvas().AddShortcut(&desktop.CustomShortcut{KeyName: binding.key, Modifier: binding.modifier}, func(fyne.Shortcut) {
+			run()
+		})
+	}
+
+	// Plain keys reach the canvas only when no widget has focus
+	app.window.Canvas().SetOnTypedKey(func(key *fyne.KeyEvent) {
+		app.onPaneKey(key)
+	})
+}
+
+// onPaneKey runs the action bound to a plain key and reports whether
+// there was one
+func (app *SFTPApp) onPaneKey(key *fyne.KeyEvent) bool {
+	run, ok := app.plainKeys[key.Name]
+	if ok {
+		run()
+	}
+	return ok
+}
+
+// switchPane moves the focus between the local and the remote pane
+func (app *SFTPApp) switchPane() {
+	pane := app.localPane
+	if app.activePane == app.localPane && app.current.client.IsConnected() {
+		pane = app.current.remotePane
+	}
+	app.activePane = pane
+	app.window.Canvas().Focus(pane.table)
+	app.current.updatePreview()
+}
+
+// openSelected enters the selected folder of the active pane or opens
+// the selected file, like a double-click
+func (app *SFTPApp) openSelected() {
+	pane := app.activePane
+	ids := pane.sel.Indices()
+	if len(ids) != 1 || ids[0] >= len(pane.entries) || pane.onDoubleClick == nil {
+		return
+	}
+	pane.onDoubleClick(pane.entries[ids[0]])
+}
+
+// goUp opens the parent folder in the active pane
+func (app *SFTPApp) goUp() {
+	if app.activePane == app.localPane {
+		app.onLocalUp()
+	} else if app.current.client.IsConnected() {
+		app.current.onRemoteUp()
+	}
+}
+
+// focusPath focuses the path entry of the active pane
+func (app *SFTPApp) focusPath() {
+	var entry fyne.Focusable = app.localPath
+	if app.activePane != app.localPane {
+		entry = app.current.remotePath
+	}
+	app.window.Canvas().Focus(entry)
+}
+
+// showNewSession selects the New Session tab and focuses the host entry
+func (app *SFTPApp) showNewSession() {
+	app.tabs.Select(app.newSessionTab)
+	app.window.Canvas().Focus(app.hostEntry)
+}
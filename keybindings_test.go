@@ -0,0 +1,86 @@
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"fyne.io/fyne/v2"
+)
+
+func TestParseKeyBinding(t *testing.T) {
+	tests := []struct {
+		text string
+		want keyBinding
+	}{
+		{"F5", keyBinding{key: fyne.KeyF5}},
+		{"Return", keyBinding{key: fyne.KeyReturn}},
+		{"Ctrl+u", keyBinding{key: fyne.KeyU, modifier: fyne.KeyModifierShortcutDefault}},
+		{"Ctrl + Shift + D", keyBinding{key: fyne.KeyD, modifier: fyne.KeyModifierShortcutDefault | fyne.KeyModifierShift}},
+	}
+	for _, tt := range tests {
+		if got, err := parseKeyBinding(tt.text); err != nil || got != tt.want {
+			t.Errorf("parseKeyBinding(%q) = %+v, %v; want %+v", tt.text, got, err, tt.want)
+		}
+	}
+
+	for _, text := range []string{"Hyper+U", "Ctrl+"} {
+		if _, err := parseKeyBinding(text); err == nil {
+			t.Errorf("parseKeyBinding(%q) should fail", text)
+		}
+	}
+}
+
+func TestLoadKeybindings(t *testing.T) {
+	file := filepath.Join(t.TempDir(), "keybindings.json")
+
+	// A missing file is created with the defaults
+	bindings, errs := loadKeybindings(file)
+	if len(errs) != 0 || len(bindings) != len(defaultKeybindings) {
+		t.Fatalf("Expected the defaults, got %v, %v", bindings, errs)
+	}
+	if _, err := os.Stat(file); err != nil {
+		t.Errorf("Defaults were not written: %v", err)
+	}
+
+	custom := `{"refresh": "Ctrl+R", "delete": "", "upload": "Meta+U", "launch": "F9"}`
+	if err := os.WriteFile(file, []byte(custom), 0600); err != nil {
+		t.Fatal(err)
+	}
+	bindings, errs = loadKeybindings(file)
+	if len(errs) != 2 {
+		t.Errorf("Expected errors for the bad modifier and the unknown action, got %v", errs)
+	}
+	if got := bindings["refresh"]; got != (keyBinding{key: fyne.KeyR, modifier: fyne.KeyModifierShortcutDefault}) {
+		t.Errorf("refresh = %+v", got)
+	}
+	if _, ok := bindings["delete"]; ok {
+		t.Error("An empty key should disable the action")
+	}
+	if got := bindings["upload"]; got.key != fyne.KeyU {
+		t.Errorf("An invalid key should keep the default, got %+v", got)
+	}
+	if got := bindings["rename"]; got.key != fyne.KeyF2 {
+		t.Errorf("Unlisted actions should keep the default, got %+v", got)
+	}
+}
+
+func TestSFTPApp_KeyActions(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping GUI test in short mode")
+	}
+	t.Setenv("HOME", t.TempDir())
+
+	app := newTestApp()
+	actions := app.keyActions()
+	for action := range defaultKeybindings {
+		if actions[action] == nil {
+			t.Errorf("No handler for %s", action)
+		}
+	}
+
+	// F2 is a plain key handled by the panes
+	if !app.onPaneKey(&fyne.KeyEvent{Name: fyne.KeyF2}) || app.onPaneKey(&fyne.KeyEvent{Name: fyne.KeyF12}) {
+		t.Error("Only bound plain keys should be handled")
+	}
+}
@@ -0,0 +1,196 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"sync"
+	"time"
+)
+
+// Listing limits
+const (
+	// listRefreshInterval is how often the pane shows the entries of a
+	// listing that is still loading
+	listRefreshInterval = 250 * time.Millisecond
+	// linkResolvers is how many symlinks of a reply are resolved at once
+	linkResolvers = 16
+	// listQueue is how many replies are read ahead of link resolution
+	listQueue = 16
+)
+
+// ListFiles lists dir like GetFiles, handing the entries of every reply
+// of the server to batch once their symlinks are resolved. The next
+// replies are read meanwhile. It stops with the error of ctx as soon as
+// ctx is done, also while the folder is read.
+func (c *SFTPGUIClient) ListFiles(ctx context.Context, dir string, batch func([]fileEntry)) error {
+	if !c.IsConnected() {
+		return fmt.Errorf("not connected")
+	}
+
+	replies := make(chan []os.FileInfo, listQueue)
+	var readErr error
+	go func() {
+		defer close(replies)
+		readErr = c.readDir(ctx, dir, func(files []os.FileInfo) {
+			select {
+			case replies <- files:
+			case <-ctx.Done():
+			}
+		})
+	}()
+
+	for files := range replies {
+		if ctx.Err() == nil {
+			batch(c.resolveLinks(ctx, dir, files))
+		}
+	}
+	if readErr != nil {
+		return cancelled(ctx, readErr)
+	}
+	return ctx.Err()
+}
+
+// readDir reads dir a reply at a time over an SFTP session of its own.
+// When no such session can be opened the folder is read as a whole
+// through the main one.
+func (c *SFTPGUIClient) readDir(ctx context.Context, dir string, batch func([]os.FileInfo)) error {
+	c.mu.Lock()
+	openChannel := c.openChannel
+	c.mu.Unlock()
+
+	if openChannel != nil {
+		if ch, err := openChannel(); err == nil {
+			defer ch.Close()
+			stop := closeOnCancel(ctx, ch)
+			defer stop()
+			reader, err := newSFTPDirReader(ch)
+			if err == nil {
+				return cancelled(ctx, reader.readDir(dir, batch))
+			}
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+		}
+	}
+
+	files, err := c.sftpClient.ReadDirContext(ctx, dir)
+	if err != nil {
+		return cancelled(ctx, err)
+	}
+	batch(files)
+	return nil
+}
+
+// resolveLinks turns the files of a reply into entries, reading where
+// their symlinks point. Each link takes two round trips, so up to
+// linkResolvers links are resolved in parallel.
+func (c *SFTPGUIClient) resolveLinks(ctx context.Context, dir string, files []os.FileInfo) []fileEntry {
+	entries := make([]fileEntry, len(files))
+	slots := make(chan struct{}, linkResolvers)
+	var wg sync.WaitGroup
+	for i, file := range files {
+		entries[i] = newFileEntry(file)
+		if !entries[i].IsSymlink() || ctx.Err() != nil {
+			continue
+		}
+
+		slots <- struct{}{}
+		wg.Add(1)
+		go func(entry *fileEntry) {
+			defer wg.Done()
+			defer func() { <-slots }()
+			linkPath := path.Join(dir, entry.Name)
+			target, _ := c.sftpClient.ReadLink(linkPath)
+			resolved, err := c.sftpClient.Stat(linkPath)
+			entry.setLink(target, resolved, err)
+		}(&entries[i])
+	}
+	wg.Wait()
+	return entries
+}
+
+// remoteListing is a listing of the remote pane that is loading
+type remoteListing struct {
+	dir    string
+	ctx    context.Context
+	cancel context.CancelFunc
+	// done is closed once the listing finished, failed or was cancelled
+	done chan struct{}
+}
+
+// listRemote lists dir into the remote pane in the background, showing
+// the entries as they arrive. A listing still loading is cancelled.
+// Complete listings are cached.
+func (s *session) listRemote(dir string) {
+	s.stopRemoteListing()
+	ctx, cancel := context.WithCancel(context.Background())
+	listing := &remoteListing{dir: dir, ctx: ctx, cancel: cancel, done: make(chan struct{})}
+	s.listing = listing
+
+	s.remotePane.setEntries(nil, path.Dir(dir) != dir)
+	s.remotePane.setLoading(true)
+	gen := s.listings.generation(dir)
+	go func() {
+		// Closed through the dispatcher, after the last update of the pane
+		defer s.do(func() { close(listing.done) })
+
+		var files []fileEntry
+		shown := 0
+		last := time.Now()
+		// The pane is only updated while the listing is the current one
+		show := func(update func()) {
+			s.do(func() {
+				if !listing.stopped() {
+					update()
+				}
+			})
+		}
+		err := s.client.ListFiles(ctx, dir, func(batch []fileEntry) {
+			files = append(files, batch...)
+			if time.Since(last) < listRefreshInterval {
+				return
+			}
+			added := files[shown:]
+			show(func() { s.remotePane.addEntries(added) })
+			shown, last = len(files), time.Now()
+		})
+		if ctx.Err() != nil {
+			return
+		}
+		if err != nil {
+			s.logMessage(fmt.Sprintf("Error reading remote directory: %v", err))
+		} else {
+			s.listings.put(dir, gen, files)
+			s.completer.forget(dir)
+		}
+
+		added := files[shown:]
+		show(func() {
+			s.remotePane.addEntries(added)
+			s.remotePane.setLoading(false)
+			if err == nil {
+				s.updateDiskSpace()
+			}
+		})
+	}()
+}
+
+// stopRemoteListing cancels the listing that is loading, if any, and the
+// check of a listing shown from the cache
+func (s *session) stopRemoteListing() {
+	if s.revalidation != nil {
+		s.revalidation()
+		s.revalidation = nil
+	}
+	if s.listing != nil && !s.listing.stopped() {
+		s.listing.cancel()
+		s.remotePane.selectLoaded = ""
+		s.remotePane.setLoading(false)
+	}
+}
+
+func (l *remoteListing) stopped() bool {
+	return l.ctx.Err() != nil
+}
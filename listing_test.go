@@ -0,0 +1,150 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"fyne.io/fyne/v2/widget"
+)
+
+// waitForRemoteListing runs the UI updates of s until the remote
+// directory was opened and its listing has finished loading
+func waitForRemoteListing(t *testing.T, s *session) {
+	t.Helper()
+	if s.navigation != nil {
+		runUpdates(t, s.SFTPApp, s.navigation.done)
+	}
+	if s.listing != nil {
+		runUpdates(t, s.SFTPApp, s.listing.done)
+	}
+}
+
+func TestSFTPGUIClient_ListFiles(t *testing.T) {
+	client, root := newTestClient(t)
+	files := make(map[string]string)
+	for i := 0; i < 1500; i++ {
+		files[fmt.Sprintf("file%04d.txt", i)] = ""
+	}
+	writeTestFiles(t, root, files)
+	if err := os.Symlink("file0000.txt", filepath.Join(root, "link")); err != nil {
+		t.Fatal(err)
+	}
+
+	// Entries arrive a server reply at a time, with their links resolved.
+	// Without a session of its own the folder comes as a whole.
+	for _, open := range []func() (io.ReadWriteCloser, error){client.openChannel, nil} {
+		client.openChannel = open
+		var batches []int
+		total := 0
+		if err := client.ListFiles(context.Background(), root, func(batch []fileEntry) {
+			batches = append(batches, len(batch))
+			total += len(batch)
+			for _, entry := range batch {
+				if entry.Name == "link" && entry.LinkTarget != "file0000.txt" {
+					t.Errorf("Expected link to point to file0000.txt, got %q", entry.LinkTarget)
+				}
+			}
+		}); err != nil {
+			t.Fatalf("ListFiles failed: %v", err)
+		}
+		if total != 1501 {
+			t.Errorf("Expected 1501 entries, got %d", total)
+		}
+		if open != nil && len(batches) < 2 {
+			t.Errorf("Expected several batches, got %v", batches)
+		}
+	}
+
+	if err := client.ListFiles(context.Background(), filepath.Join(root, "missing"), func([]fileEntry) {}); err == nil {
+		t.Error("Expected an error listing a missing folder")
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	err := client.ListFiles(ctx, root, func([]fileEntry) {
+		t.Error("A cancelled listing should not hand over entries")
+	})
+	if !errors.Is(err, context.Canceled) {
+		t.Errorf("Expected context.Canceled, got %v", err)
+	}
+}
+
+func TestSession_ListRemote(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping GUI test in short mode")
+	}
+	t.Setenv("HOME", t.TempDir())
+
+	app := newTestApp()
+	client, root := newTestClient(t)
+	s := app.newSession(client)
+	writeTestFiles(t, root, map[string]string{"a.txt": "a", "b.txt": "b", "c.txt": "c"})
+
+	s.currentRemote = root
+	s.listRemote(root)
+	s.remotePane.selectWhenLoaded("b.txt")
+	waitForRemoteListing(t, s)
+
+	if s.remotePane.loading {
+		t.Error("The pane should stop loading once the listing finished")
+	}
+	if len(s.remotePane.all) != 4 {
+		t.Errorf("Expected 3 entries and .., got %d", len(s.remotePane.all))
+	}
+	if names := s.remotePane.selectedNames(); len(names) != 1 || names[0] != "b.txt" {
+		t.Errorf("Expected b.txt to be selected once loaded, got %v", names)
+	}
+	if _, _, ok := s.listings.get(root); !ok {
+		t.Error("A complete listing should be cached")
+	}
+}
+
+func TestSession_SelectWhileListing(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping GUI test in short mode")
+	}
+	t.Setenv("HOME", t.TempDir())
+
+	app := newTestApp()
+	client, root := newTestClient(t)
+	s := app.newSession(client)
+	files := make(map[string]string)
+	for i := 0; i < 3000; i++ {
+		files[fmt.Sprintf("file%04d.txt", i)] = ""
+	}
+	writeTestFiles(t, root, files)
+
+	// Rows are clicked from the event goroutine while the batches arrive,
+	// with the updates of the listing running between the clicks
+	s.currentRemote = root
+	s.listRemote(root)
+	timeout := time.After(10 * time.Second)
+	for i := 0; ; i++ {
+		// Alternate rows so that no two clicks make a double click
+		if len(s.remotePane.entries) > 2 {
+			s.remotePane.table.Select(widget.TableCellID{Row: 1 + i%2})
+		}
+		app.ui.flush()
+		select {
+		case <-s.listing.done:
+		case <-timeout:
+			t.Fatal("Remote listing did not finish")
+		default:
+			continue
+		}
+		break
+	}
+
+	if names := s.remotePane.selectedNames(); len(names) > 1 {
+		t.Errorf("Clicking rows should select one at a time, got %d selected", len(names))
+	}
+	if len(s.remotePane.entries) != 3001 {
+		t.Errorf("Expected 3000 entries and .., got %d", len(s.remotePane.entries))
+	}
+}
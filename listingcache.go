@@ -0,0 +1,211 @@
+package main
+
+import (
+	"container/list"
+	"context"
+	"fmt"
+	"path"
+	"strings"
+	"sync"
+	"time"
+)
+
+// listingCacheTTL is how long a cached remote listing is shown without
+// asking the server again
+const listingCacheTTL = 30 * time.Second
+
+// listingCacheMaxEntries bounds the entries held by the cached listings
+// of a session. The least recently used listings are dropped beyond it.
+const listingCacheMaxEntries = 250000
+
+// listingCache keeps the remote folder listings of a session
+type listingCache struct {
+	mu   sync.Mutex
+	dirs map[string]*list.Element // of *cachedDir
+	// lru orders the listings from the most to the least recently used
+	lru  *list.List
+	size int // entries held by all listings
+	// gens counts how often each folder that was listed has been
+	// forgotten, so that a listing read across that is not cached
+	gens map[string]int
+}
+
+type cachedDir struct {
+	dir   string
+	files []fileEntry
+	at    time.Time
+}
+
+func newListingCache() *listingCache {
+	return &listingCache{dirs: make(map[string]*list.Element), lru: list.New(), gens: make(map[string]int)}
+}
+
+// get returns the cached listing of dir and whether it is younger than
+// listingCacheTTL
+func (c *listingCache) get(dir string) (files []fileEntry, fresh, ok bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	elem, ok := c.dirs[dir]
+	if !ok {
+		return nil, false, false
+	}
+	c.lru.MoveToFront(elem)
+	cached := elem.Value.(*cachedDir)
+	return cached.files, time.Since(cached.at) < listingCacheTTL, true
+}
+
+// generation returns the generation of dir, to be passed to put with a
+// listing of dir read after the call
+func (c *listingCache) generation(dir string) int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// Recorded so that forgetTree also sees folders being listed
+	gen := c.gens[dir]
+	c.gens[dir] = gen
+	return gen
+}
+
+// put caches a listing of dir read under generation gen. It is dropped
+// when dir was forgotten since, as the listing may predate the change.
+// Listings used least recently are dropped to stay within
+// listingCacheMaxEntries, except the one just cached.
+func (c *listingCache) put(dir string, gen int, files []fileEntry) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.gens[dir] != gen {
+		return
+	}
+	c.remove(dir)
+	c.dirs[dir] = c.lru.PushFront(&cachedDir{dir: dir, files: files, at: time.Now()})
+	c.size += len(files)
+	for c.size > listingCacheMaxEntries && c.lru.Len() > 1 {
+		c.remove(c.lru.Back().Value.(*cachedDir).dir)
+	}
+}
+
+// remove drops the listing of dir. c.mu must be held.
+func (c *listingCache) remove(dir string) {
+	if elem, ok := c.dirs[dir]; ok {
+		c.size -= len(elem.Value.(*cachedDir).files)
+		c.lru.Remove(elem)
+		delete(c.dirs, dir)
+	}
+}
+
+// forget drops the listing of dir
+func (c *listingCache) forget(dir string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.remove(dir)
+	c.gens[dir]++
+}
+
+// forgetTree drops the listings of dir and of every folder below it
+func (c *listingCache) forgetTree(dir string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	prefix := strings.TrimSuffix(dir, "/") + "/"
+	for listed := range c.gens {
+		if listed == dir || strings.HasPrefix(listed, prefix) {
+			c.remove(listed)
+			c.gens[listed]++
+		}
+	}
+}
+
+// staleEntries compares a cached listing with a fresh one. It returns the
+// names of the cached entries that changed or were removed, and the
+// number of new entries.
+func staleEntries(cached, fresh []fileEntry) (stale map[string]bool, added int) {
+	current := make(map[string]fileEntry, len(fresh))
+	for _, entry := range fresh {
+		current[entry.Name] = entry
+	}
+
+	stale = make(map[string]bool)
+	for _, entry := range cached {
+		now, ok := current[entry.Name]
+		if !ok || now.Size != entry.Size || !now.ModTime.Equal(entry.ModTime) || now.Mode != entry.Mode {
+			stale[entry.Name] = true
+		}
+		delete(current, entry.Name)
+	}
+	return stale, len(current)
+}
+
+// remoteChanged drops the cached listings an operation of ours made
+// outdated: those of the folders holding paths and, for folders, of
+// everything below them
+func (s *session) remoteChanged(paths ...string) {
+	for _, p := range paths {
+		s.listings.forget(path.Dir(p))
+		s.listings.forgetTree(p)
+		s.completer.forget(path.Dir(p))
+		s.completer.forget(p)
+	}
+}
+
+// updateRemoteFiles shows the current remote folder, from the cache when
+// it has the listing. Listings older than listingCacheTTL are shown right
+// away and checked against the server in the background.
+func (s *session) updateRemoteFiles() {
+	if !s.client.IsConnected() {
+		return
+	}
+
+	dir := s.currentRemote
+	files, fresh, ok := s.listings.get(dir)
+	if !ok {
+		s.listRemote(dir)
+		return
+	}
+	s.showRemoteFiles(files)
+	if !fresh {
+		s.revalidateRemoteFiles(dir, files)
+	}
+}
+
+// reloadRemoteFiles lists the current remote folder from the server
+func (s *session) reloadRemoteFiles() {
+	if !s.client.IsConnected() {
+		return
+	}
+	s.listRemote(s.currentRemote)
+}
+
+func (s *session) showRemoteFiles(files []fileEntry) {
+	s.stopRemoteListing()
+	s.remotePane.setEntries(files, path.Dir(s.currentRemote) != s.currentRemote)
+	s.updateDiskSpace()
+}
+
+// revalidateRemoteFiles lists dir again in the background. Entries shown
+// from the cache that changed on the server are marked stale rather than
+// replaced, so the selection is kept until the user refreshes. Leaving
+// the folder stops the check.
+func (s *session) revalidateRemoteFiles(dir string, cached []fileEntry) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.revalidation = cancel
+	gen := s.listings.generation(dir)
+	go func() {
+		defer cancel()
+		files, err := s.client.GetFilesContext(ctx, dir)
+		if err != nil {
+			return
+		}
+		s.listings.put(dir, gen, files)
+
+		stale, added := staleEntries(cached, files)
+		if len(stale) == 0 && added == 0 {
+			return
+		}
+		s.do(func() {
+			if s.currentRemote != dir {
+				return
+			}
+			s.remotePane.markStale(stale)
+			s.logMessage(fmt.Sprintf("%s changed on the server: %d entries changed or removed, %d new. Refresh to update.",
+				dir, len(stale), added))
+		})
+	}()
+}
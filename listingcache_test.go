@@ -0,0 +1,137 @@
+package main
+
+import (
+	"path"
+	"testing"
+	"time"
+)
+
+func TestListingCache(t *testing.T) {
+	c := newListingCache()
+	for _, dir := range []string{"/srv", "/srv/app", "/srv/app/logs", "/srvx"} {
+		c.put(dir, c.generation(dir), []fileEntry{{Name: "f"}})
+	}
+
+	if files, fresh, ok := c.get("/srv/app"); !ok || !fresh || len(files) != 1 {
+		t.Errorf("get(/srv/app) = %v, %v, %v", files, fresh, ok)
+	}
+	if _, _, ok := c.get("/var"); ok {
+		t.Error("get should miss folders that were never listed")
+	}
+
+	c.forgetTree("/srv/app")
+	for dir, want := range map[string]bool{"/srv": true, "/srv/app": false, "/srv/app/logs": false, "/srvx": true} {
+		if _, _, ok := c.get(dir); ok != want {
+			t.Errorf("After forgetTree, cached %s = %v, want %v", dir, ok, want)
+		}
+	}
+
+	// A listing read before its folder was forgotten is not cached,
+	// including folders that were being listed for the first time
+	gen, newGen := c.generation("/srv/app"), c.generation("/srv/app/new")
+	c.forgetTree("/srv/app")
+	c.put("/srv/app", gen, []fileEntry{{Name: "f"}})
+	c.put("/srv/app/new", newGen, []fileEntry{{Name: "f"}})
+	for _, dir := range []string{"/srv/app", "/srv/app/new"} {
+		if _, _, ok := c.get(dir); ok {
+			t.Errorf("A listing of %s read before forgetTree should be dropped", dir)
+		}
+	}
+	gen = c.generation("/srv")
+	c.forget("/srv")
+	c.put("/srv", gen, nil)
+	if _, _, ok := c.get("/srv"); ok {
+		t.Error("A listing read before forget should be dropped")
+	}
+	c.put("/srv", c.generation("/srv"), []fileEntry{{Name: "f"}})
+
+	c.dirs["/srv"].Value.(*cachedDir).at = time.Now().Add(-2 * listingCacheTTL)
+	if _, fresh, ok := c.get("/srv"); !ok || fresh {
+		t.Errorf("An old listing should be returned as stale, got fresh=%v ok=%v", fresh, ok)
+	}
+}
+
+func TestListingCache_Evict(t *testing.T) {
+	c := newListingCache()
+	half := make([]fileEntry, listingCacheMaxEntries/2)
+	for _, dir := range []string{"/a", "/b"} {
+		c.put(dir, c.generation(dir), half)
+	}
+	// Using /a makes /b the least recently used listing
+	c.get("/a")
+	c.put("/c", c.generation("/c"), half)
+	for dir, want := range map[string]bool{"/a": true, "/b": false, "/c": true} {
+		if _, _, ok := c.get(dir); ok != want {
+			t.Errorf("Cached %s = %v, want %v", dir, ok, want)
+		}
+	}
+	if c.size != 2*len(half) {
+		t.Errorf("Expected %d cached entries, got %d", 2*len(half), c.size)
+	}
+
+	// A single listing larger than the limit is still cached
+	c.put("/huge", c.generation("/huge"), make([]fileEntry, listingCacheMaxEntries+1))
+	if _, _, ok := c.get("/huge"); !ok || c.lru.Len() != 1 {
+		t.Errorf("Expected only /huge to be cached, got %d listings", c.lru.Len())
+	}
+}
+
+func TestStaleEntries(t *testing.T) {
+	now := time.Now()
+	cached := []fileEntry{
+		{Name: "same", Size: 1, ModTime: now},
+		{Name: "grown", Size: 1, ModTime: now},
+		{Name: "touched", Size: 1, ModTime: now},
+		{Name: "removed", Size: 1, ModTime: now},
+	}
+	fresh := []fileEntry{
+		{Name: "same", Size: 1, ModTime: now},
+		{Name: "grown", Size: 2, ModTime: now},
+		{Name: "touched", Size: 1, ModTime: now.Add(time.Minute)},
+		{Name: "new", Size: 1, ModTime: now},
+	}
+
+	stale, added := staleEntries(cached, fresh)
+	if len(stale) != 3 || !stale["grown"] || !stale["touched"] || !stale["removed"] {
+		t.Errorf("stale = %v, want grown, touched and removed", stale)
+	}
+	if added != 1 {
+		t.Errorf("added = %d, want 1", added)
+	}
+}
+
+func TestSession_RemoteListingCache(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping GUI test in short mode")
+	}
+	t.Setenv("HOME", t.TempDir())
+
+	app := newTestApp()
+	client, root := newTestClient(t)
+	s := app.newSession(client)
+	writeTestFiles(t, root, map[string]string{"a.txt": "a"})
+
+	s.currentRemote = root
+	s.updateRemoteFiles()
+	waitForRemoteListing(t, s)
+	if _, _, ok := s.listings.get(root); !ok {
+		t.Fatal("Listing the folder should cache it")
+	}
+
+	// Files added behind our back only show up after a refresh
+	writeTestFiles(t, root, map[string]string{"b.txt": "b"})
+	s.updateRemoteFiles()
+	if files, _, _ := s.listings.get(root); len(files) != 1 {
+		t.Errorf("Expected the cached listing to be shown, got %d entries", len(files))
+	}
+	s.onRefresh()
+	waitForRemoteListing(t, s)
+	if files, _, _ := s.listings.get(root); len(files) != 2 {
+		t.Errorf("Refresh should list the server again, got %d entries", len(files))
+	}
+
+	s.remoteChanged(path.Join(root, "c.txt"))
+	if _, _, ok := s.listings.get(root); ok {
+		t.Error("remoteChanged should drop the listing of the parent folder")
+	}
+}
@@ -0,0 +1,58 @@
+//go:build !windows
+// +build !windows
+
+package main
+
+import (
+	"os"
+	"os/user"
+	"strconv"
+	"sync"
+	"syscall"
+)
+
+// ownerNames caches uid and gid lookups, keyed by "u<id>" and "g<id>"
+var ownerNames sync.Map
+
+// localOwner returns the "user:group" owning a local file
+func localOwner(info os.FileInfo) string {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return ""
+	}
+
+	return lookupOwnerName("u", uint64(stat.Uid)) + ":" + lookupOwnerName("g", uint64(stat.Gid))
+}
+
+func lookupOwnerName(kind string, id uint64) string {
+	key := kind + strconv.FormatUint(id, 10)
+	if name, ok := ownerNames.Load(key); ok {
+		return name.(string)
+	}
+
+	name := strconv.FormatUint(id, 10)
+	if kind == "u" {
+		if u, err := user.LookupId(name); err == nil {
+			name = u.Username
+		}
+	} else if g, err := user.LookupGroupId(name); err == nil {
+		name = g.Name
+	}
+	ownerNames.Store(key, name)
+	return name
+}
+
+// isHiddenLocal reports whether a local file is hidden by the OS beyond
+// the dotfile convention. Unix systems have no such attribute.
+func isHiddenLocal(info os.FileInfo) bool {
+	return false
+}
+
+// localIDs returns the numeric owner and group of a local file
+func localIDs(info os.FileInfo) (uid, gid int, ok bool) {
+	stat, ok := info.Sys().(*syscall.Stat_t)
+	if !ok {
+		return -1, -1, false
+	}
+	return int(stat.Uid), int(stat.Gid), true
+}
@@ -0,0 +1,27 @@
+//go:build windows
+// +build windows
+
+package main
+
+import (
+	"os"
+	"syscall"
+)
+
+// localOwner returns the owner of a local file. Windows ACL owners are
+// not shown in the file table.
+func localOwner(info os.FileInfo) string {
+	return ""
+}
+
+// isHiddenLocal reports whether a local file has the hidden attribute set
+func isHiddenLocal(info os.FileInfo) bool {
+	attrs, ok := info.Sys().(*syscall.Win32FileAttributeData)
+	return ok && attrs.FileAttributes&syscall.FILE_ATTRIBUTE_HIDDEN != 0
+}
+
+// localIDs returns the numeric owner and group of a local file. Windows
+// files have no numeric owner.
+func localIDs(info os.FileInfo) (uid, gid int, ok bool) {
+	return -1, -1, false
+}
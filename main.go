@@ -1,20 +1,20 @@
 package main
 
 import (
+	"context"
 	"encoding/json"
 	"fmt"
 	"io"
 	"os"
 	"os/exec"
+	"path"
 	"path/filepath"
 	"runtime"
-	"strings"
-	"time"
+	"sync"
 
 	"fyne.io/fyne/v2"
 	"fyne.io/fyne/v2/app"
 	"fyne.io/fyne/v2/container"
-	"fyne.io/fyne/v2/data/binding"
 	"fyne.io/fyne/v2/dialog"
 	"fyne.io/fyne/v2/layout"
 	"fyne.io/fyne/v2/theme"
@@ -37,29 +37,38 @@ type Bookmark struct {
 type SFTPGUIClient struct {
 	sshClient  *ssh.Client
 	sftpClient *sftp.Client
-	connected  bool
+	// openChannel opens another SFTP session for reading folders a reply
+	// at a time. Without it folders are read through sftpClient.
+	openChannel func() (io.ReadWriteCloser, error)
+
+	// mu guards connected, which background operations check while the
+	// connection may be closing
+	mu        sync.Mutex
+	connected bool
 }
 
 // SFTPApp represents the main application
 type SFTPApp struct {
 	app    fyne.App
 	window fyne.Window
-	client *SFTPGUIClient
+
+	// Connections, each shown in its own tab before the New Session tab.
+	// home backs the New Session tab; it never connects and keeps the
+	// local side usable while no session is selected.
+	sessions      []*session
+	current       *session
+	home          *session
+	tabs          *container.AppTabs
+	newSessionTab *container.TabItem
 
 	// Connection widgets
-	hostEntry         *widget.Entry
-	portEntry         *widget.Entry
-	userEntry         *widget.Entry
-	passEntry         *widget.Entry
-	keyEntry          *widget.Entry
-	useKeyCheck       *widget.Check
-	connectBtn        *widget.Button
-	disconnectBtn     *widget.Button
-	statusLabel       *widget.Label
-	connectionPanel   *fyne.Container
-	connectionContent *fyne.Container
-	collapseBtn       *widget.Button
-	isCollapsed       bool
+	hostEntry   *widget.Entry
+	portEntry   *widget.Entry
+	userEntry   *widget.Entry
+	passEntry   *widget.Entry
+	keyEntry    *widget.Entry
+	useKeyCheck *widget.Check
+	connectBtn  *widget.Button
 
 	// Bookmark widgets
 	bookmarkSelect    *widget.Select
@@ -69,11 +78,26 @@ type SFTPApp struct {
 	bookmarks         []Bookmark
 	bookmarksFile     string
 
+	// Persisted preferences
+	settings     Settings
+	settingsFile string
+
+	// Keyboard actions bound to keys without modifiers
+	plainKeys       map[fyne.KeyName]func()
+	keybindingsFile string
+
+	// Edit sessions of the current session
+	editRows *fyne.Container
+
+	// File preview, shown in browserArea beside the panes when enabled
+	preview      *previewPanel
+	browserArea  *fyne.Container
+	browserPanes fyne.CanvasObject
+	previewSplit *container.Split
+
 	// File browser widgets
-	remoteList *widget.List
-	localList  *widget.List
-	remotePath *widget.Entry
-	localPath  *widget.Entry
+	localPane *filePane
+	localPath *widget.Entry
 
 	// Operation buttons
 	uploadBtn   *widget.Button
@@ -82,12 +106,19 @@ type SFTPApp struct {
 	mkdirBtn    *widget.Button
 	refreshBtn  *widget.Button
 	openBtn     *widget.Button
-
-	// Status and progress
-	progressBar *widget.ProgressBar
-	logArea     *widget.Entry
-
-	// Activity log collapse
+	renameBtn   *widget.Button
+	propsBtn    *widget.Button
+	symlinkBtn  *widget.Button
+	editBtn     *widget.Button
+	compareBtn  *widget.Button
+	dirCompBtn  *widget.Button
+	sizeBtn     *widget.Button
+	sendBtn     *widget.Button
+
+	// Progress bar of the current session
+	progressArea *fyne.Container
+
+	// Activity log collapse. logContent shows the log of the current session.
 	logPanel       *fyne.Container
 	logContent     *fyne.Container
 	logCollapseBtn *widget.Button
@@ -97,14 +128,18 @@ type SFTPApp struct {
 	footerPanel      *fyne.Container
 	connectionStatus *widget.Label
 	footerDisconnect *widget.Button
+	diskSpaceLabel   *widget.Label
 
-	// Data bindings
-	remoteFiles    binding.StringList
-	localFiles     binding.StringList
-	currentRemote  string
-	currentLocal   string
-	selectedRemote string
-	selectedLocal  string
+	// Current local directory
+	currentLocal string
+
+	// activePane is the pane the user last clicked into
+	activePane *filePane
+	// drag tracks entries dragged out of either pane
+	drag *dragTracker
+
+	// ui applies the UI updates of background operations
+	ui *uiDispatcher
 }
 
 // NewSFTPGUIClient creates a new SFTP client
@@ -116,76 +151,18 @@ func NewSFTPGUIClient() *SFTPGUIClient {
 
 // Connect establishes connection with password authentication
 func (c *SFTPGUIClient) Connect(host, username, password string, port int) error {
-	config := &ssh.ClientConfig{
-		User: username,
-		Auth: []ssh.AuthMethod{
-			ssh.Password(password),
-		},
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
-		Timeout:         30 * time.Second,
-	}
-
-	addr := fmt.Sprintf("%s:%d", host, port)
-	sshClient, err := ssh.Dial("tcp", addr, config)
-	if err != nil {
-		return fmt.Errorf("failed to connect to SSH server: %v", err)
-	}
-
-	sftpClient, err := sftp.NewClient(sshClient)
-	if err != nil {
-		sshClient.Close()
-		return fmt.Errorf("failed to create SFTP client: %v", err)
-	}
-
-	c.sshClient = sshClient
-	c.sftpClient = sftpClient
-	c.connected = true
-
-	return nil
+	return c.ConnectContext(context.Background(), host, username, password, port)
 }
 
 // ConnectWithKey establishes connection with key authentication
 func (c *SFTPGUIClient) ConnectWithKey(host, username, keyPath string, port int) error {
-	key, err := os.ReadFile(keyPath)
-	if err != nil {
-		return fmt.Errorf("unable to read private key: %v", err)
-	}
-
-	signer, err := ssh.ParsePrivateKey(key)
-	if err != nil {
-		return fmt.Errorf("unable to parse private key: %v", err)
-	}
-
-	config := &ssh.ClientConfig{
-		User: username,
-		Auth: []ssh.AuthMethod{
-			ssh.PublicKeys(signer),
-		},
-		HostKeyCallback: ssh.InsecureIgnoreHostKey(),
-		Timeout:         30 * time.Second,
-	}
-
-	addr := fmt.Sprintf("%s:%d", host, port)
-	sshClient, err := ssh.Dial("tcp", addr, config)
-	if err != nil {
-		return fmt.Errorf("failed to connect to SSH server: %v", err)
-	}
-
-	sftpClient, err := sftp.NewClient(sshClient)
-	if err != nil {
-		sshClient.Close()
-		return fmt.Errorf("failed to create SFTP client: %v", err)
-	}
-
-	c.sshClient = sshClient
-	c.sftpClient = sftpClient
-	c.connected = true
-
-	return nil
+	return c.ConnectWithKeyContext(context.Background(), host, username, keyPath, port)
 }
 
 // Disconnect closes the connection
 func (c *SFTPGUIClient) Disconnect() error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	if !c.connected {
 		return nil
 	}
@@ -203,30 +180,23 @@ func (c *SFTPGUIClient) Disconnect() error {
 
 // IsConnected returns connection status
 func (c *SFTPGUIClient) IsConnected() bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
 	return c.connected
 }
 
-// GetFiles returns files in the specified directory
-func (c *SFTPGUIClient) GetFiles(path string) ([]string, error) {
-	if !c.connected {
-		return nil, fmt.Errorf("not connected")
-	}
-
-	files, err := c.sftpClient.ReadDir(path)
-	if err != nil {
-		return nil, err
+// RealPath resolves a remote path to its absolute canonical form
+func (c *SFTPGUIClient) RealPath(path string) (string, error) {
+	if !c.IsConnected() {
+		return "", fmt.Errorf("not connected")
 	}
 
-	var fileList []string
-	for _, file := range files {
-		prefix := "📄 "
-		if file.IsDir() {
-			prefix = "📁 "
-		}
-		fileList = append(fileList, prefix+file.Name())
-	}
+	return c.sftpClient.RealPath(path)
+}
 
-	return fileList, nil
+// GetFiles returns the entries of the specified directory
+func (c *SFTPGUIClient) GetFiles(dir string) ([]fileEntry, error) {
+	return c.GetFilesContext(context.Background(), dir)
 }
 
 // NewSFTPApp creates a new SFTP GUI application
@@ -257,12 +227,16 @@ func NewSFTPApp() *SFTPApp {
 	sftpApp := &SFTPApp{
 		app:           myApp,
 		window:        window,
-		client:        NewSFTPGUIClient(),
 		bookmarksFile: bookmarksFile,
+		settingsFile:  filepath.Join(configDir, "settings.json"),
+
+		keybindingsFile: filepath.Join(configDir, "keybindings.json"),
+		ui:              newUIDispatcher(fyne.Do),
 	}
 
-	// Load bookmarks before setting up UI
+	// Load bookmarks and settings before setting up UI
 	sftpApp.loadBookmarks()
+	sftpApp.loadSettings()
 	sftpApp.setupUI()
 	return sftpApp
 }
@@ -302,45 +276,45 @@ func migrateOldBookmarks(oldPath, newPath string) {
 
 // setupUI creates and configures the user interface
 func (app *SFTPApp) setupUI() {
-	// Initialize data bindings
-	app.remoteFiles = binding.NewStringList()
-	app.localFiles = binding.NewStringList()
-
-	// Create connection panel
-	connectionPanel := app.createConnectionPanel()
+	// Create status panel
+	statusPanel := app.createStatusPanel()
 
-	// Create file browser panel
+	// Create file browser panel with the session tabs
 	browserPanel := app.createBrowserPanel()
 
 	// Create control panel
 	controlPanel := app.createControlPanel()
 
-	// Create status panel
-	statusPanel := app.createStatusPanel()
-
 	// Create footer panel
 	footerPanel := app.createFooterPanel()
 
+	app.activePane = app.localPane
+	app.switchSession(app.home)
+
 	// Create main layout with proper separation
 	mainContent := container.New(layout.NewBorderLayout(nil, statusPanel, nil, nil),
 		statusPanel,
 		browserPanel,
 	)
 
-	content := container.New(layout.NewBorderLayout(connectionPanel, footerPanel, nil, controlPanel),
-		connectionPanel,
+	content := container.New(layout.NewBorderLayout(nil, footerPanel, nil, controlPanel),
 		footerPanel,
 		controlPanel,
 		mainContent,
 	)
 
 	app.window.SetContent(content)
+	app.window.SetOnClosed(app.closeAllSessions)
+	app.window.SetOnDropped(func(_ fyne.Position, uris []fyne.URI) {
+		app.current.onFilesDropped(uris)
+	})
+	app.setupShortcuts()
 
 	// Initialize local directory
-	app.updateLocalFiles()
+	app.navigateLocal(".", false)
 }
 
-// createConnectionPanel creates the connection configuration panel
+// createConnectionPanel creates the connection form of the New Session tab
 func (app *SFTPApp) createConnectionPanel() fyne.CanvasObject {
 	app.hostEntry = widget.NewEntry()
 	app.hostEntry.SetPlaceHolder("Host (e.g., example.com)")
@@ -379,11 +353,6 @@ func (app *SFTPApp) createConnectionPanel() fyne.CanvasObject {
 	})
 
 	app.connectBtn = widget.NewButtonWithIcon("Connect", theme.ConfirmIcon(), app.onConnect)
-	app.disconnectBtn = widget.NewButtonWithIcon("Disconnect", theme.CancelIcon(), app.onDisconnect)
-	app.disconnectBtn.Disable()
-
-	app.statusLabel = widget.NewLabel("Disconnected")
-	app.statusLabel.TextStyle.Bold = true
 
 	// Layout connection form
 	form := container.NewGridWithColumns(2,
@@ -395,7 +364,7 @@ func (app *SFTPApp) createConnectionPanel() fyne.CanvasObject {
 	)
 
 	authPanel := container.NewHBox(app.useKeyCheck)
-	buttonPanel := container.NewHBox(app.connectBtn, app.disconnectBtn, layout.NewSpacer(), app.statusLabel)
+	buttonPanel := container.NewHBox(app.connectBtn)
 
 	// Create bookmark widgets
 	app.bookmarkSelect = widget.NewSelect(app.getBookmarkNames(), func(selected string) {
@@ -427,27 +396,8 @@ func (app *SFTPApp) createConnectionPanel() fyne.CanvasObject {
 		app.deleteBookmarkBtn,
 	)
 
-	// Create the connection details content
-	app.connectionContent = container.NewVBox(bookmarkPanel, form, authPanel, buttonPanel)
-
-	// Create collapse/expand button
-	app.collapseBtn = widget.NewButtonWithIcon("", theme.MenuDropDownIcon(), func() {
-		app.toggleConnectionPanel()
-	})
-	app.collapseBtn.Resize(fyne.NewSize(30, 30))
-
-	// Create header with collapse button
-	headerPanel := container.NewBorder(nil, nil, nil, app.collapseBtn,
-		widget.NewLabelWithStyle("Connection", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}))
-
-	// Create main connection panel
-	app.connectionPanel = container.NewVBox(
-		headerPanel,
-		app.connectionContent,
-	)
-
-	app.isCollapsed = false
-	return app.connectionPanel
+	// Each successful connection opens a new session tab
+	return container.NewScroll(container.NewVBox(bookmarkPanel, form, authPanel, buttonPanel))
 }
 
 // createBrowserPanel creates the file browser panel
@@ -456,148 +406,170 @@ func (app *SFTPApp) createBrowserPanel() fyne.CanvasObject {
 	app.localPath = widget.NewEntry()
 	app.localPath.SetText(".")
 	app.localPath.OnSubmitted = func(path string) {
-		app.currentLocal = path
-		app.updateLocalFiles()
+		app.navigateLocal(path, true)
 	}
 
 	localBrowseBtn := widget.NewButton("Browse", func() {
 		dialog.ShowFolderOpen(func(uri fyne.ListableURI, err error) {
 			if err == nil && uri != nil {
-				app.localPath.SetText(uri.Path())
-				app.currentLocal = uri.Path()
-				app.updateLocalFiles()
+				app.navigateLocal(uri.Path(), true)
 			}
 		}, app.window)
 	})
 
-	app.localList = widget.NewListWithData(app.localFiles,
-		func() fyne.CanvasObject {
-			return widget.NewLabel("template")
-		},
-		func(item binding.DataItem, obj fyne.CanvasObject) {
-			label := obj.(*widget.Label)
-			strItem := item.(binding.String)
-			val, _ := strItem.Get()
-			label.SetText(val)
-		},
-	)
-	// Add double-click functionality to open local files
-	var lastClickTime time.Time
-	var lastClickID widget.ListItemID = -1
-	app.localList.OnSelected = func(id widget.ListItemID) {
-		if val, err := app.localFiles.GetValue(id); err == nil {
-			app.selectedLocal = strings.TrimPrefix(val, "📄 ")
-			app.selectedLocal = strings.TrimPrefix(app.selectedLocal, "📁 ")
-
-			// Check for double-click (within 500ms)
-			now := time.Now()
-			if id == lastClickID && now.Sub(lastClickTime) < 500*time.Millisecond {
-				// Double-click detected - open the file if it's not a directory
-				if !strings.HasPrefix(val, "📁 ") {
-					app.onOpen()
-				}
-			}
-			lastClickTime = now
-			lastClickID = id
-		}
+	app.drag = newDragTracker()
+	app.drag.onDrop = func(src *filePane, entries []fileEntry, dst *filePane, target *fileEntry) {
+		app.current.onEntriesDropped(src, entries, dst, target)
 	}
 
-	// Remote file browser
-	app.remotePath = widget.NewEntry()
-	app.remotePath.SetPlaceHolder("Remote path")
-	app.remotePath.OnSubmitted = func(path string) {
-		if app.client.IsConnected() {
-			app.currentRemote = path
-			app.updateRemoteFiles()
+	app.localPane = newFilePane(app.settings.Local,
+		newPaneNav(app.onLocalBack, app.onLocalForward, app.onLocalUp), app.drag)
+	app.localPane.onSettingsChanged = func(settings paneSettings) {
+		app.settings.Local = settings
+		app.saveSettings()
+	}
+	app.localPane.onActivated = func() { app.activePane = app.localPane }
+	app.localPane.onSelectionChanged = func() {
+		if app.activePane == app.localPane {
+			app.current.updatePreview()
 		}
 	}
-
-	app.remoteList = widget.NewListWithData(app.remoteFiles,
-		func() fyne.CanvasObject {
-			return widget.NewLabel("template")
-		},
-		func(item binding.DataItem, obj fyne.CanvasObject) {
-			label := obj.(*widget.Label)
-			strItem := item.(binding.String)
-			val, _ := strItem.Get()
-			label.SetText(val)
-		},
-	)
-	app.remoteList.OnSelected = func(id widget.ListItemID) {
-		if val, err := app.remoteFiles.GetValue(id); err == nil {
-			app.selectedRemote = strings.TrimPrefix(val, "📄 ")
-			app.selectedRemote = strings.TrimPrefix(app.selectedRemote, "📁 ")
+	app.localPane.onRename = app.renameLocalEntry
+	app.localPane.onKey = app.onPaneKey
+	app.localPane.onContextMenu = func() *fyne.Menu {
+		return app.current.contextMenu(app.localPane)
+	}
+	app.localPane.onDoubleClick = func(entry fileEntry) {
+		// Double-click enters directories and opens files
+		if entry.IsDir || entry.LinkDir {
+			app.navigateLocal(filepath.Join(app.currentLocal, entry.Name), true)
+		} else {
+			app.onOpen()
 		}
 	}
 
 	localPanel := container.NewBorder(
-		container.NewBorder(nil, nil, widget.NewLabel("Local Path:"), localBrowseBtn, app.localPath),
-		nil, nil, nil,
-		container.NewScroll(app.localList),
+		container.NewVBox(
+			container.NewBorder(nil, nil, app.localPane.nav.buttons(), localBrowseBtn, app.localPath),
+			container.NewHScroll(app.localPane.nav.crumbs),
+			app.localPane.filter.content(),
+		),
+		app.localPane.selectionBar(),
+		nil, nil,
+		app.localPane.table,
 	)
 
-	remotePanel := container.NewBorder(
-		container.NewBorder(nil, nil, widget.NewLabel("Remote Path:"), nil, app.remotePath),
-		nil, nil, nil,
-		container.NewScroll(app.remoteList),
-	)
+	// Sessions show their remote pane in a tab, next to the connection form
+	app.home = app.newSession(NewSFTPGUIClient())
+	app.newSessionTab = container.NewTabItemWithIcon("New Session", theme.ContentAddIcon(), app.createConnectionPanel())
+	app.tabs = container.NewAppTabs(app.newSessionTab)
+	app.tabs.OnSelected = func(item *container.TabItem) {
+		app.switchSession(app.sessionForTab(item))
+	}
 
-	return container.NewHSplit(
+	app.browserPanes = container.NewHSplit(
 		widget.NewCard("Local Files", "", localPanel),
-		widget.NewCard("Remote Files", "", remotePanel),
+		widget.NewCard("Remote Files", "", app.tabs),
 	)
+	app.preview = newPreviewPanel()
+	app.previewSplit = container.NewHSplit(app.browserPanes, app.preview.card)
+	app.previewSplit.Offset = 0.7
+	app.browserArea = container.NewStack()
+	app.setPreviewVisible(app.settings.ShowPreview)
+	return app.browserArea
 }
 
 // createControlPanel creates the control buttons panel
 func (app *SFTPApp) createControlPanel() fyne.CanvasObject {
-	app.uploadBtn = widget.NewButtonWithIcon("Upload", theme.UploadIcon(), app.onUpload)
+	app.uploadBtn = widget.NewButtonWithIcon("Upload", theme.UploadIcon(), app.inSession((*session).onUpload))
 	app.uploadBtn.Disable()
 
-	app.downloadBtn = widget.NewButtonWithIcon("Download", theme.DownloadIcon(), app.onDownload)
+	app.downloadBtn = widget.NewButtonWithIcon("Download", theme.DownloadIcon(), app.inSession((*session).onDownload))
 	app.downloadBtn.Disable()
 
-	app.deleteBtn = widget.NewButtonWithIcon("Delete", theme.DeleteIcon(), app.onDelete)
-	app.deleteBtn.Disable()
+	app.deleteBtn = widget.NewButtonWithIcon("Delete", theme.DeleteIcon(), app.inSession((*session).onDelete))
 
-	app.mkdirBtn = widget.NewButtonWithIcon("New Folder", theme.FolderNewIcon(), app.onMkdir)
-	app.mkdirBtn.Disable()
+	app.mkdirBtn = widget.NewButtonWithIcon("New Folder", theme.FolderNewIcon(), app.inSession((*session).onMkdir))
 
-	app.refreshBtn = widget.NewButtonWithIcon("Refresh", theme.ViewRefreshIcon(), app.onRefresh)
+	app.refreshBtn = widget.NewButtonWithIcon("Refresh", theme.ViewRefreshIcon(), app.inSession((*session).onRefresh))
 	app.refreshBtn.Disable()
 
 	app.openBtn = widget.NewButtonWithIcon("Open", theme.DocumentIcon(), app.onOpen)
 
+	app.editBtn = widget.NewButtonWithIcon("Edit", theme.DocumentCreateIcon(), app.inSession((*session).onEdit))
+	app.editBtn.Disable()
+
+	app.compareBtn = widget.NewButtonWithIcon("Compare", theme.ViewRestoreIcon(), app.inSession((*session).onCompare))
+
+	app.dirCompBtn = widget.NewButtonWithIcon("Compare Folders", theme.ListIcon(), app.inSession((*session).showDirCompare))
+	app.dirCompBtn.Disable()
+
+	app.sizeBtn = widget.NewButtonWithIcon("Folder Size", theme.StorageIcon(), app.inSession((*session).onFolderSize))
+	app.sizeBtn.Disable()
+
+	app.sendBtn = widget.NewButtonWithIcon("Send to Session", theme.MailSendIcon(), app.inSession((*session).onSendToSession))
+	app.sendBtn.Disable()
+
+	app.renameBtn = widget.NewButtonWithIcon("Rename", theme.DocumentCreateIcon(), app.inSession((*session).onRename))
+
+	app.propsBtn = widget.NewButtonWithIcon("Properties", theme.InfoIcon(), app.inSession((*session).onProperties))
+
+	app.symlinkBtn = widget.NewButtonWithIcon("New Symlink", theme.MailAttachmentIcon(), app.inSession((*session).onCreateSymlink))
+
+	followCheck := widget.NewCheck("Follow symlinks", func(follow bool) {
+		app.settings.FollowLinks = follow
+		app.saveSettings()
+	})
+	followCheck.Checked = app.settings.FollowLinks
+
+	previewCheck := widget.NewCheck("Show preview", func(show bool) {
+		app.setPreviewVisible(show)
+		app.saveSettings()
+	})
+	previewCheck.Checked = app.settings.ShowPreview
+
 	return container.NewVBox(
 		widget.NewCard("Operations", "",
 			container.NewVBox(
 				app.uploadBtn,
 				app.downloadBtn,
+				app.sendBtn,
 				widget.NewSeparator(),
 				app.openBtn,
+				app.editBtn,
+				app.compareBtn,
+				app.dirCompBtn,
+				app.sizeBtn,
+				app.renameBtn,
+				app.propsBtn,
 				widget.NewSeparator(),
 				app.deleteBtn,
 				app.mkdirBtn,
+				app.symlinkBtn,
 				widget.NewSeparator(),
 				app.refreshBtn,
+				followCheck,
+				previewCheck,
 			),
 		),
+		app.createEditSessionsPanel(),
 	)
 }
 
-// createStatusPanel creates the status and progress panel
-func (app *SFTPApp) createStatusPanel() fyne.CanvasObject {
-	app.progressBar = widget.NewProgressBar()
-	app.progressBar.Hide()
-
-	app.logArea = widget.NewMultiLineEntry()
-	app.logArea.SetPlaceHolder("Activity log will appear here...")
-	app.logArea.Wrapping = fyne.TextWrapWord
+// inSession returns a handler running op on the current session
+func (app *SFTPApp) inSession(op func(*session)) func() {
+	return func() {
+		op(app.current)
+	}
+}
 
-	logScroll := container.NewScroll(app.logArea)
-	logScroll.SetMinSize(fyne.NewSize(0, 150))
+// createStatusPanel creates the status and progress panel. It shows the
+// progress bar and log of the current session.
+func (app *SFTPApp) createStatusPanel() fyne.CanvasObject {
+	app.progressArea = container.NewStack()
 
 	// Create the log content
-	app.logContent = container.NewVBox(logScroll)
+	app.logContent = container.NewStack()
 
 	// Create collapse/expand button for log
 	app.logCollapseBtn = widget.NewButtonWithIcon("", theme.MenuDropDownIcon(), func() {
@@ -618,12 +590,15 @@ func (app *SFTPApp) createStatusPanel() fyne.CanvasObject {
 	app.isLogCollapsed = false
 
 	return container.NewVBox(
-		app.progressBar,
+		app.progressArea,
 		app.logPanel,
 	)
 }
 
 // Event handlers
+
+// onConnect opens a new session with the details of the connection form.
+// The connection is made in the background so other sessions stay usable.
 func (app *SFTPApp) onConnect() {
 	host := app.hostEntry.Text
 	port := 22
@@ -631,41 +606,56 @@ func (app *SFTPApp) onConnect() {
 		fmt.Sscanf(portText, "%d", &port)
 	}
 	username := app.userEntry.Text
+	useKey, keyPath, password := app.useKeyCheck.Checked, app.keyEntry.Text, app.passEntry.Text
 
 	if host == "" || username == "" {
 		app.showError("Please enter host and username")
 		return
 	}
+	if useKey && keyPath == "" {
+		app.showError("Please select SSH key file")
+		return
+	}
+	if !useKey && password == "" {
+		app.showError("Please enter password")
+		return
+	}
 
-	app.showProgress("Connecting...")
+	s := app.newSession(NewSFTPGUIClient())
+	s.host, s.user, s.port = host, username, port
+	s.title = fmt.Sprintf("%s@%s", username, host)
+	app.home.showProgress(fmt.Sprintf("Connecting to %s...", s.title))
+	app.connectBtn.Disable()
 
-	var err error
-	if app.useKeyCheck.Checked {
-		keyPath := app.keyEntry.Text
-		if keyPath == "" {
-			app.showError("Please select SSH key file")
-			app.hideProgress()
-			return
+	ctx, done := app.home.startOperation()
+	go func() {
+		defer done()
+		var err error
+		if useKey {
+			err = s.client.ConnectWithKeyContext(ctx, host, username, keyPath, port)
+		} else {
+			err = s.client.ConnectContext(ctx, host, username, password, port)
 		}
-		err = app.client.ConnectWithKey(host, username, keyPath, port)
-	} else {
-		password := app.passEntry.Text
-		if password == "" {
-			app.showError("Please enter password")
-			app.hideProgress()
-			return
+		if err == nil && ctx.Err() != nil {
+			// Cancelled just as the connection came up
+			s.client.Disconnect()
+			err = ctx.Err()
 		}
-		err = app.client.Connect(host, username, password, port)
-	}
-
-	app.hideProgress()
-
-	if err != nil {
-		app.showError(fmt.Sprintf("Connection failed: %v", err))
-		return
-	}
 
-	app.onConnected()
+		app.home.hideProgress()
+		if ctx.Err() != nil {
+			app.home.logMessage(fmt.Sprintf("Connection to %s cancelled", s.title))
+		} else if err != nil {
+			app.home.showError(fmt.Sprintf("Connection failed: %v", err))
+		}
+		app.do(func() {
+			app.connectBtn.Enable()
+			if err == nil {
+				app.addSession(s)
+				s.onConnected()
+			}
+		})
+	}()
 }
 
 // createFooterPanel creates the footer with connection status and disconnect button
@@ -675,167 +665,108 @@ func (app *SFTPApp) createFooterPanel() fyne.CanvasObject {
 	app.connectionStatus.TextStyle.Bold = true
 
 	// Create footer disconnect button
-	app.footerDisconnect = widget.NewButtonWithIcon("Disconnect", theme.CancelIcon(), app.onDisconnect)
+	app.footerDisconnect = widget.NewButtonWithIcon("Disconnect", theme.CancelIcon(), app.inSession((*session).onDisconnect))
 	app.footerDisconnect.Disable()
 
+	// Free space of the current remote directory, when the server reports it
+	app.diskSpaceLabel = widget.NewLabel("")
+
 	// Create footer panel with clean design
 	app.footerPanel = container.NewBorder(
 		nil, nil,
 		app.connectionStatus,
-		app.footerDisconnect,
+		container.NewHBox(app.diskSpaceLabel, app.footerDisconnect),
 		widget.NewSeparator(),
 	)
 
 	return app.footerPanel
 }
 
-func (app *SFTPApp) onDisconnect() {
-	app.client.Disconnect()
-	app.onDisconnected()
+func (s *session) onDisconnect() {
+	s.client.Disconnect()
+	s.onDisconnected()
 }
 
-func (app *SFTPApp) onConnected() {
-	app.statusLabel.SetText("Connected")
-	app.connectBtn.Disable()
-	app.disconnectBtn.Enable()
-
-	// Enable operation buttons
-	app.uploadBtn.Enable()
-	app.downloadBtn.Enable()
-	app.deleteBtn.Enable()
-	app.mkdirBtn.Enable()
-	app.refreshBtn.Enable()
-
-	app.logMessage("Connected successfully")
-
-	// Set initial remote path and load files
-	app.remotePath.SetText(".")
-	app.currentRemote = "."
-	app.updateRemoteFiles()
-
-	// Collapse the connection panel to save space
-	if !app.isCollapsed {
-		app.toggleConnectionPanel()
+func (s *session) onUpload() {
+	names := s.localPane.selectedNames()
+	if len(names) == 0 {
+		s.showError("Please select local files to upload")
+		return
 	}
 
-	// Update footer status
-	app.connectionStatus.SetText("🔵 Connected")
-	app.footerDisconnect.Enable()
-
-	// Collapse the activity log to save space
-	if !app.isLogCollapsed {
-		app.toggleLogPanel()
+	paths := make([]string, len(names))
+	for i, name := range names {
+		paths[i] = filepath.Join(s.currentLocal, name)
 	}
+	s.uploadPaths(paths, s.currentRemote)
 }
 
-func (app *SFTPApp) onDisconnected() {
-	app.statusLabel.SetText("Disconnected")
-	app.connectBtn.Enable()
-	app.disconnectBtn.Disable()
-
-	// Disable operation buttons
-	app.uploadBtn.Disable()
-	app.downloadBtn.Disable()
-	app.deleteBtn.Disable()
-	app.mkdirBtn.Disable()
-	app.refreshBtn.Disable()
-
-	// Clear remote files
-	app.remoteFiles.Set([]string{})
-
-	app.logMessage("Disconnected")
-
-	// Expand the connection panel when disconnected
-	if app.isCollapsed {
-		app.toggleConnectionPanel()
+func (s *session) onDownload() {
+	names := s.remotePane.selectedNames()
+	if len(names) == 0 {
+		s.showError("Please select remote files to download")
+		return
 	}
 
-	// Update bookmark selection state
-	app.bookmarkSelect.ClearSelected()
-	app.deleteBookmarkBtn.Disable()
-	app.quickConnectBtn.Disable()
-
-	// Update footer status
-	app.connectionStatus.SetText("🔴 Disconnected")
-	app.footerDisconnect.Disable()
-
-	// Expand the activity log when disconnected
-	if app.isLogCollapsed {
-		app.toggleLogPanel()
+	paths := make([]string, len(names))
+	for i, name := range names {
+		paths[i] = path.Join(s.currentRemote, name)
 	}
+	s.downloadPaths(paths, s.currentLocal)
 }
 
-func (app *SFTPApp) onUpload() {
-	if app.selectedLocal == "" {
-		app.showError("Please select a local file to upload")
-		return
-	}
-
-	localFile := filepath.Join(app.currentLocal, app.selectedLocal)
-	remoteFile := app.currentRemote + "/" + app.selectedLocal
-
-	app.showProgress("Uploading...")
-
+// runBatch runs op for each name as a single job. The progress bar
+// tracks the aggregate progress, failures are logged per entry and a
+// summary is reported once every entry has been processed. op runs in
+// the background, so it must only use values captured beforehand;
+// onFinish runs through the dispatcher. Cancelling the batch cancels the
+// context given to op and skips the entries not started yet.
+func (s *session) runBatch(action, done string, names []string, op func(ctx context.Context, name string) error, onFinish func()) {
+	s.setProgress(0)
+	s.showProgress(fmt.Sprintf("%s of %d item(s) started...", action, len(names)))
+
+	ctx, finished := s.startOperation()
 	go func() {
-		err := app.uploadFile(localFile, remoteFile)
-		app.hideProgress()
-
-		if err != nil {
-			app.showError(fmt.Sprintf("Upload failed: %v", err))
-		} else {
-			app.logMessage(fmt.Sprintf("Uploaded: %s", app.selectedLocal))
-			app.updateRemoteFiles()
+		defer finished()
+		failed := 0
+		for i, name := range names {
+			err := op(ctx, name)
+			if ctx.Err() != nil {
+				s.hideProgress()
+				s.logMessage(fmt.Sprintf("%s cancelled after %d of %d item(s)", action, i, len(names)))
+				if onFinish != nil {
+					s.do(onFinish)
+				}
+				return
+			}
+			if err != nil {
+				failed++
+				s.logMessage(fmt.Sprintf("%s failed for %s: %v", action, name, err))
+			} else {
+				s.logMessage(fmt.Sprintf("%s: %s", done, name))
+			}
+			s.setProgress(float64(i+1) / float64(len(names)))
 		}
-	}()
-}
-
-func (app *SFTPApp) onDownload() {
-	if app.selectedRemote == "" {
-		app.showError("Please select a remote file to download")
-		return
-	}
+		s.hideProgress()
 
-	remoteFile := app.currentRemote + "/" + app.selectedRemote
-	localFile := filepath.Join(app.currentLocal, app.selectedRemote)
-
-	app.showProgress("Downloading...")
-
-	go func() {
-		err := app.downloadFile(remoteFile, localFile)
-		app.hideProgress()
-
-		if err != nil {
-			app.showError(fmt.Sprintf("Download failed: %v", err))
-		} else {
-			app.logMessage(fmt.Sprintf("Downloaded: %s", app.selectedRemote))
-			app.updateLocalFiles()
+		if failed > 0 {
+			s.showError(fmt.Sprintf("%s failed for %d of %d item(s)", action, failed, len(names)))
+		} else if len(names) > 1 {
+			s.logMessage(fmt.Sprintf("%s %d items", done, len(names)))
+		}
+		if onFinish != nil {
+			s.do(onFinish)
 		}
 	}()
 }
 
-func (app *SFTPApp) onDelete() {
-	if app.selectedRemote == "" {
-		app.showError("Please select a remote file to delete")
+// onMkdir creates a folder in the current directory of the active pane
+func (s *session) onMkdir() {
+	remote := s.activePane == s.remotePane
+	if remote && !s.client.IsConnected() {
 		return
 	}
 
-	dialog.ShowConfirm("Confirm Delete",
-		fmt.Sprintf("Are you sure you want to delete '%s'?", app.selectedRemote),
-		func(confirmed bool) {
-			if confirmed {
-				remoteFile := app.currentRemote + "/" + app.selectedRemote
-				err := app.client.sftpClient.Remove(remoteFile)
-				if err != nil {
-					app.showError(fmt.Sprintf("Delete failed: %v", err))
-				} else {
-					app.logMessage(fmt.Sprintf("Deleted: %s", app.selectedRemote))
-					app.updateRemoteFiles()
-				}
-			}
-		}, app.window)
-}
-
-func (app *SFTPApp) onMkdir() {
 	entry := widget.NewEntry()
 	entry.SetPlaceHolder("Enter directory name")
 
@@ -844,31 +775,57 @@ func (app *SFTPApp) onMkdir() {
 			widget.NewFormItem("Directory Name", entry),
 		},
 		func(confirmed bool) {
-			if confirmed && entry.Text != "" {
-				remotePath := app.currentRemote + "/" + entry.Text
-				err := app.client.sftpClient.Mkdir(remotePath)
-				if err != nil {
-					app.showError(fmt.Sprintf("Create directory failed: %v", err))
-				} else {
-					app.logMessage(fmt.Sprintf("Created directory: %s", entry.Text))
-					app.updateRemoteFiles()
+			if !confirmed || entry.Text == "" {
+				return
+			}
+			name := entry.Text
+			if !remote {
+				if err := os.Mkdir(filepath.Join(s.currentLocal, name), 0755); err != nil {
+					s.showError(fmt.Sprintf("Create directory failed: %v", err))
+					return
 				}
+				s.logMessage(fmt.Sprintf("Created directory: %s", name))
+				s.updateLocalFiles()
+				return
 			}
-		}, app.window)
+
+			// The server is asked in the background
+			dir := path.Join(s.currentRemote, name)
+			_, done := s.startOperation()
+			go func() {
+				defer done()
+				if err := s.client.sftpClient.Mkdir(dir); err != nil {
+					s.showError(fmt.Sprintf("Create directory failed: %v", err))
+					return
+				}
+				s.remoteChanged(dir)
+				s.logMessage(fmt.Sprintf("Created directory: %s", name))
+				s.do(s.updateRemoteFiles)
+			}()
+		}, s.window)
 }
 
-func (app *SFTPApp) onRefresh() {
-	app.updateRemoteFiles()
-	app.updateLocalFiles()
+// onRefresh lists both panes again, bypassing the remote listing cache
+func (s *session) onRefresh() {
+	s.reloadRemoteFiles()
+	s.updateLocalFiles()
 }
 
 func (app *SFTPApp) onOpen() {
-	if app.selectedLocal == "" {
+	names := app.localPane.selectedNames()
+	if len(names) == 0 {
 		app.showError("Please select a local file to open")
 		return
 	}
 
-	localFile := filepath.Join(app.currentLocal, app.selectedLocal)
+	for _, name := range names {
+		app.openLocalFile(name)
+	}
+}
+
+// openLocalFile opens a single file of the current local directory
+func (app *SFTPApp) openLocalFile(name string) {
+	localFile := filepath.Join(app.currentLocal, name)
 
 	// Check if it's a file (not a directory)
 	if info, err := os.Stat(localFile); err != nil {
@@ -884,7 +841,7 @@ func (app *SFTPApp) onOpen() {
 	if err != nil {
 		app.showError(fmt.Sprintf("Failed to open file: %v", err))
 	} else {
-		app.logMessage(fmt.Sprintf("Opened file: %s", app.selectedLocal))
+		app.logMessage(fmt.Sprintf("Opened file: %s", name))
 	}
 }
 
@@ -895,92 +852,86 @@ func (app *SFTPApp) updateLocalFiles() {
 		app.logMessage(fmt.Sprintf("Error reading local directory: %v", err))
 		return
 	}
-	app.localFiles.Set(files)
-}
-
-func (app *SFTPApp) updateRemoteFiles() {
-	if !app.client.IsConnected() {
-		return
-	}
-
-	files, err := app.client.GetFiles(app.currentRemote)
-	if err != nil {
-		app.logMessage(fmt.Sprintf("Error reading remote directory: %v", err))
-		return
-	}
-	app.remoteFiles.Set(files)
+	app.localPane.setEntries(files, filepath.Dir(app.currentLocal) != app.currentLocal)
 }
 
-func (app *SFTPApp) getLocalFiles(path string) ([]string, error) {
-	files, err := os.ReadDir(path)
+func (app *SFTPApp) getLocalFiles(dir string) ([]fileEntry, error) {
+	files, err := os.ReadDir(dir)
 	if err != nil {
 		return nil, err
 	}
 
-	var fileList []string
+	entries := make([]fileEntry, 0, len(files))
 	for _, file := range files {
-		prefix := "📄 "
-		if file.IsDir() {
-			prefix = "📁 "
+		info, err := file.Info()
+		if err != nil {
+			// The file vanished between ReadDir and Info
+			continue
+		}
+		entry := newFileEntry(info)
+		entry.Hidden = entry.Hidden || isHiddenLocal(info)
+		if entry.IsSymlink() {
+			linkPath := filepath.Join(dir, file.Name())
+			target, _ := os.Readlink(linkPath)
+			resolved, err := os.Stat(linkPath)
+			entry.setLink(target, resolved, err)
 		}
-		fileList = append(fileList, prefix+file.Name())
+		entries = append(entries, entry)
 	}
 
-	return fileList, nil
+	return entries, nil
 }
 
-func (app *SFTPApp) uploadFile(localPath, remotePath string) error {
-	localFile, err := os.Open(localPath)
-	if err != nil {
-		return err
-	}
-	defer localFile.Close()
-
-	remoteFile, err := app.client.sftpClient.Create(remotePath)
-	if err != nil {
-		return err
+// uploadFile copies one local file to the server, stopping once ctx is
+// done
+func (s *session) uploadFile(ctx context.Context, localPath, remotePath string) error {
+	if !s.settings.FollowLinks {
+		// Recreate symlinks instead of copying what they point to
+		if info, err := os.Lstat(localPath); err == nil && info.Mode()&os.ModeSymlink != 0 {
+			target, err := os.Readlink(localPath)
+			if err != nil {
+				return err
+			}
+			return s.client.sftpClient.Symlink(filepath.ToSlash(target), remotePath)
+		}
 	}
-	defer remoteFile.Close()
 
-	_, err = io.Copy(remoteFile, localFile)
-	return err
+	return s.client.UploadFileContext(ctx, localPath, remotePath)
 }
 
-func (app *SFTPApp) downloadFile(remotePath, localPath string) error {
-	remoteFile, err := app.client.sftpClient.Open(remotePath)
-	if err != nil {
-		return err
-	}
-	defer remoteFile.Close()
-
-	localFile, err := os.Create(localPath)
-	if err != nil {
-		return err
+// downloadFile copies one remote file to this computer, stopping once ctx
+// is done
+func (s *session) downloadFile(ctx context.Context, remotePath, localPath string) error {
+	if !s.settings.FollowLinks {
+		// Recreate symlinks instead of copying what they point to
+		if info, err := s.client.sftpClient.Lstat(remotePath); err == nil && info.Mode()&os.ModeSymlink != 0 {
+			target, err := s.client.ReadLink(remotePath)
+			if err != nil {
+				return err
+			}
+			return os.Symlink(filepath.FromSlash(target), localPath)
+		}
 	}
-	defer localFile.Close()
 
-	_, err = io.Copy(localFile, remoteFile)
-	return err
+	return s.client.DownloadFileContext(ctx, remotePath, localPath)
 }
 
+// showProgress shows the progress bar of the current session
 func (app *SFTPApp) showProgress(message string) {
-	app.progressBar.Show()
-	app.logMessage(message)
+	app.current.showProgress(message)
 }
 
 func (app *SFTPApp) hideProgress() {
-	app.progressBar.Hide()
+	app.current.hideProgress()
 }
 
+// logMessage adds a line to the log of the current session
 func (app *SFTPApp) logMessage(message string) {
-	timestamp := time.Now().Format("15:04:05")
-	logEntry := fmt.Sprintf("[%s] %s\n", timestamp, message)
-	app.logArea.SetText(app.logArea.Text + logEntry)
+	app.current.logMessage(message)
 }
 
 func (app *SFTPApp) showError(message string) {
-	dialog.ShowError(fmt.Errorf(message), app.window)
-	app.logMessage("ERROR: " + message)
+	app.current.showError(message)
 }
 
 // Run starts the application
@@ -988,26 +939,6 @@ func (app *SFTPApp) Run() {
 	app.window.ShowAndRun()
 }
 
-func (app *SFTPApp) toggleConnectionPanel() {
-	if app.isCollapsed {
-		// Expand
-		app.connectionPanel.Objects = []fyne.CanvasObject{
-			app.connectionPanel.Objects[0], // header
-			app.connectionContent,
-		}
-		app.collapseBtn.SetIcon(theme.MenuDropDownIcon())
-		app.isCollapsed = false
-	} else {
-		// Collapse
-		app.connectionPanel.Objects = []fyne.CanvasObject{
-			app.connectionPanel.Objects[0], // header only
-		}
-		app.collapseBtn.SetIcon(theme.MenuDropUpIcon())
-		app.isCollapsed = true
-	}
-	app.connectionPanel.Refresh()
-}
-
 func (app *SFTPApp) toggleLogPanel() {
 	if app.isLogCollapsed {
 		// Expand
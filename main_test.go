@@ -1,6 +1,8 @@
 package main
 
 import (
+	"os"
+	"path/filepath"
 	"testing"
 )
 
@@ -87,15 +89,67 @@ func TestNewSFTPApp(t *testing.T) {
 		t.Fatal("NewSFTPApp() returned nil")
 	}
 
-	if app.client == nil {
-		t.Error("SFTPApp should have a client")
+	if app.current == nil || app.current != app.home {
+		t.Fatal("SFTPApp should start in the New Session tab")
 	}
 
-	if app.client.IsConnected() {
+	if app.current.client.IsConnected() {
 		t.Error("New app client should not be connected")
 	}
 }
 
+func TestSFTPApp_Sessions(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping GUI test in short mode")
+	}
+	t.Setenv("HOME", t.TempDir())
+
+	app := newTestApp()
+	client, root := newTestClient(t)
+	writeTestFiles(t, root, map[string]string{"a.txt": "a", "b.txt": "b"})
+
+	s := app.newSession(client)
+	s.title = "test@localhost"
+	app.addSession(s)
+	s.onConnected()
+	s.navigateRemote(root, true)
+	waitForRemoteListing(t, s)
+	checkSessionTabs(t, app, s)
+}
+
+// checkSessionTabs checks the tab of a connected session with its two
+// remote files, then closes it
+func checkSessionTabs(t *testing.T, app *SFTPApp, s *session) {
+	if app.current != s || len(app.tabs.Items) != 2 || app.tabs.Selected() != s.tab {
+		t.Fatal("Connected session should get its own selected tab")
+	}
+	files := 0
+	for _, entry := range s.remotePane.all {
+		if !entry.IsParent() {
+			files++
+		}
+	}
+	if files != 2 {
+		t.Errorf("Expected 2 remote entries, got %d", files)
+	}
+	if app.uploadBtn.Disabled() {
+		t.Error("Upload should be enabled for a connected session")
+	}
+
+	// Switching to the New Session tab disables the remote operations
+	app.tabs.Select(app.newSessionTab)
+	if app.current != app.home || !app.uploadBtn.Disabled() {
+		t.Error("Selecting the New Session tab should switch to the home session")
+	}
+
+	// The test connection is closed by the test server cleanup
+	app.tabs.Select(s.tab)
+	s.onDisconnected()
+	if len(app.sessions) != 0 || len(app.tabs.Items) != 1 || app.current != app.home {
+		t.Error("Disconnecting should close the session tab")
+	}
+}
+
 // Benchmark tests
 func BenchmarkNewSFTPGUIClient(b *testing.B) {
 	for i := 0; i < b.N; i++ {
@@ -128,3 +182,49 @@ func ExampleSFTPGUIClient_basic() {
 	_ = connected
 	// Output:
 }
+
+func TestSFTPGUIClient_RealPath(t *testing.T) {
+	client, root := newTestClient(t)
+
+	abs, err := client.RealPath(".")
+	if err != nil {
+		t.Fatalf("RealPath failed: %v", err)
+	}
+	if abs != root {
+		t.Errorf("Expected RealPath(\".\") to return %s, got %s", root, abs)
+	}
+}
+
+func TestSFTPGUIClient_GetFiles(t *testing.T) {
+	client, root := newTestClient(t)
+
+	if err := os.WriteFile(filepath.Join(root, "notes.txt"), []byte("hello"), 0644); err != nil {
+		t.Fatal(err)
+	}
+	if err := os.Mkdir(filepath.Join(root, "docs"), 0755); err != nil {
+		t.Fatal(err)
+	}
+
+	entries, err := client.GetFiles(root)
+	if err != nil {
+		t.Fatalf("GetFiles failed: %v", err)
+	}
+	if len(entries) != 2 {
+		t.Fatalf("Expected 2 entries, got %d", len(entries))
+	}
+
+	for _, entry := range entries {
+		switch entry.Name {
+		case "notes.txt":
+			if entry.IsDir || entry.Size != 5 {
+				t.Errorf("Unexpected file entry: %+v", entry)
+			}
+		case "docs":
+			if !entry.IsDir {
+				t.Errorf("Expected docs to be a directory: %+v", entry)
+			}
+		default:
+			t.Errorf("Unexpected entry %q", entry.Name)
+		}
+	}
+}
@@ -0,0 +1,310 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// navHistory keeps the back and forward stacks of visited directories
+type navHistory struct {
+	back    []string
+	forward []string
+}
+
+// Visit records leaving from for a newly visited directory
+func (h *navHistory) Visit(from string) {
+	h.back = append(h.back, from)
+	h.forward = nil
+}
+
+// Back returns the previous directory, pushing current onto the forward stack
+func (h *navHistory) Back(current string) (string, bool) {
+	if len(h.back) == 0 {
+		return "", false
+	}
+	prev := h.back[len(h.back)-1]
+	h.back = h.back[:len(h.back)-1]
+	h.forward = append(h.forward, current)
+	return prev, true
+}
+
+// Forward returns the next directory, pushing current onto the back stack
+func (h *navHistory) Forward(current string) (string, bool) {
+	if len(h.forward) == 0 {
+		return "", false
+	}
+	next := h.forward[len(h.forward)-1]
+	h.forward = h.forward[:len(h.forward)-1]
+	h.back = append(h.back, current)
+	return next, true
+}
+
+// Reset clears both stacks
+func (h *navHistory) Reset() {
+	h.back = nil
+	h.forward = nil
+}
+
+// breadcrumb is a clickable segment of a directory path
+type breadcrumb struct {
+	label string
+	path  string
+}
+
+// remoteBreadcrumbs splits an absolute remote (POSIX) path into segments
+func remoteBreadcrumbs(p string) []breadcrumb {
+	crumbs := []breadcrumb{{label: "/", path: "/"}}
+	current := "/"
+	for _, part := range strings.Split(strings.Trim(p, "/"), "/") {
+		if part == "" {
+			continue
+		}
+		current = path.Join(current, part)
+		crumbs = append(crumbs, breadcrumb{label: part, path: current})
+	}
+	return crumbs
+}
+
+// localBreadcrumbs splits an absolute local path into segments
+func localBreadcrumbs(p string) []breadcrumb {
+	sep := string(filepath.Separator)
+	volume := filepath.VolumeName(p)
+	root := volume + sep
+	crumbs := []breadcrumb{{label: root, path: root}}
+	current := root
+	for _, part := range strings.Split(strings.Trim(p[len(volume):], sep), sep) {
+		if part == "" {
+			continue
+		}
+		current = filepath.Join(current, part)
+		crumbs = append(crumbs, breadcrumb{label: part, path: current})
+	}
+	return crumbs
+}
+
+// paneNav holds the navigation controls and history of a file pane
+type paneNav struct {
+	history    navHistory
+	backBtn    *widget.Button
+	forwardBtn *widget.Button
+	upBtn      *widget.Button
+	crumbs     *fyne.Container
+}
+
+// newPaneNav creates the back, forward and up buttons and an empty breadcrumb bar
+func newPaneNav(onBack, onForward, onUp func()) *paneNav {
+	nav := &paneNav{
+		backBtn:    widget.NewButtonWithIcon("", theme.NavigateBackIcon(), onBack),
+		forwardBtn: widget.NewButtonWithIcon("", theme.NavigateNextIcon(), onForward),
+		upBtn:      widget.NewButtonWithIcon("Up", theme.MoveUpIcon(), onUp),
+		crumbs:     container.NewHBox(),
+	}
+	nav.backBtn.Disable()
+	nav.forwardBtn.Disable()
+	return nav
+}
+
+// buttons returns the navigation buttons laid out in a row
+func (n *paneNav) buttons() fyne.CanvasObject {
+	return container.NewHBox(n.backBtn, n.forwardBtn, n.upBtn)
+}
+
+// update rebuilds the breadcrumb bar and refreshes the button states
+func (n *paneNav) update(crumbs []breadcrumb, onClick func(string)) {
+	objects := make([]fyne.CanvasObject, 0, len(crumbs))
+	for i, crumb := range crumbs {
+		target := crumb.path
+		btn := widget.NewButton(crumb.label, func() { onClick(target) })
+		if i == len(crumbs)-1 {
+			btn.Importance = widget.HighImportance
+		} else {
+			btn.Importance = widget.LowImportance
+		}
+		objects = append(objects, btn)
+	}
+	n.crumbs.Objects = objects
+	n.crumbs.Refresh()
+	n.refreshButtons()
+}
+
+// reset clears the history and breadcrumbs
+func (n *paneNav) reset() {
+	n.history.Reset()
+	n.crumbs.Objects = nil
+	n.crumbs.Refresh()
+	n.refreshButtons()
+}
+
+func (n *paneNav) refreshButtons() {
+	if len(n.history.back) > 0 {
+		n.backBtn.Enable()
+	} else {
+		n.backBtn.Disable()
+	}
+	if len(n.history.forward) > 0 {
+		n.forwardBtn.Enable()
+	} else {
+		n.forwardBtn.Disable()
+	}
+}
+
+// navigateLocal changes the local directory and reports whether it did.
+// When record is set the directory being left is pushed onto the back
+// history.
+func (app *SFTPApp) navigateLocal(dir string, record bool) bool {
+	if !filepath.IsAbs(dir) && app.currentLocal != "" {
+		dir = filepath.Join(app.currentLocal, dir)
+	}
+	abs, err := filepath.Abs(dir)
+	if err != nil {
+		app.logMessage(fmt.Sprintf("Error resolving local path: %v", err))
+		return false
+	}
+	if info, err := os.Stat(abs); err != nil {
+		app.logMessage(fmt.Sprintf("Error reading local directory: %v", err))
+		return false
+	} else if !info.IsDir() {
+		app.logMessage(fmt.Sprintf("Not a directory: %s", abs))
+		return false
+	}
+
+	if record && app.currentLocal != "" && app.currentLocal != abs {
+		app.localPane.nav.history.Visit(app.currentLocal)
+	}
+	if app.currentLocal != abs {
+		app.localPane.filter.clear()
+	}
+	app.currentLocal = abs
+	app.localPath.SetText(abs)
+	app.localPane.nav.update(localBreadcrumbs(abs), func(p string) { app.navigateLocal(p, true) })
+	app.updateLocalFiles()
+	return true
+}
+
+// remoteNavigation is a change of the remote directory whose path is
+// being resolved
+type remoteNavigation struct {
+	cancel context.CancelFunc
+	// done is closed once the directory was opened or could not be
+	done chan struct{}
+}
+
+// navigateRemote changes the remote directory. When record is set the
+// directory being left is pushed onto the back history.
+func (s *session) navigateRemote(dir string, record bool) {
+	s.openRemote(dir, func(abs string) {
+		if record && s.currentRemote != "" && s.currentRemote != abs {
+			s.remotePane.nav.history.Visit(s.currentRemote)
+		}
+	})
+}
+
+// openRemote changes the remote directory in the background. The path is
+// normalized with RealPath so the path bar always shows an absolute path,
+// and checked to be a directory; a navigation still resolving is
+// cancelled. When the directory checks out, visit updates the history on
+// the UI goroutine before the directory changes.
+func (s *session) openRemote(dir string, visit func(abs string)) {
+	if !s.client.IsConnected() {
+		return
+	}
+	if !path.IsAbs(dir) && s.currentRemote != "" {
+		dir = path.Join(s.currentRemote, dir)
+	}
+	if s.navigation != nil {
+		s.navigation.cancel()
+	}
+
+	opCtx, done := s.startOperation()
+	ctx, cancel := context.WithCancel(opCtx)
+	nav := &remoteNavigation{cancel: cancel, done: make(chan struct{})}
+	s.navigation = nav
+	go func() {
+		// failure is logged in place of opening the directory
+		failure := ""
+		abs, err := s.client.RealPath(dir)
+		if err != nil {
+			failure = fmt.Sprintf("Error resolving remote path: %v", err)
+		} else if info, err := s.client.sftpClient.Stat(abs); err != nil {
+			failure = fmt.Sprintf("Error reading remote directory: %v", err)
+		} else if !info.IsDir() {
+			failure = fmt.Sprintf("Not a directory: %s", abs)
+		}
+
+		// The operation ends once the result was applied, as ending it
+		// cancels ctx
+		s.do(func() {
+			defer close(nav.done)
+			defer done()
+			if ctx.Err() != nil {
+				return
+			}
+			if failure != "" {
+				s.logMessage(failure)
+				return
+			}
+
+			visit(abs)
+			if s.currentRemote != abs {
+				s.remotePane.filter.clear()
+			}
+			s.currentRemote = abs
+			s.remotePath.SetText(abs)
+			s.remotePane.nav.update(remoteBreadcrumbs(abs), func(p string) { s.navigateRemote(p, true) })
+			s.updateRemoteFiles()
+		})
+	}()
+}
+
+// The history moves before navigating. When the directory cannot be
+// opened the opposite move puts the entry back, so it is not lost.
+
+func (app *SFTPApp) onLocalBack() {
+	history := &app.localPane.nav.history
+	if dir, ok := history.Back(app.currentLocal); ok && !app.navigateLocal(dir, false) {
+		history.Forward(dir)
+		app.localPane.nav.refreshButtons()
+	}
+}
+
+func (app *SFTPApp) onLocalForward() {
+	history := &app.localPane.nav.history
+	if dir, ok := history.Forward(app.currentLocal); ok && !app.navigateLocal(dir, false) {
+		history.Back(dir)
+		app.localPane.nav.refreshButtons()
+	}
+}
+
+func (app *SFTPApp) onLocalUp() {
+	app.navigateLocal(filepath.Dir(app.currentLocal), true)
+}
+
+// Remote directories open in the background, so the history only moves
+// once the directory was opened.
+
+func (s *session) onRemoteBack() {
+	history := &s.remotePane.nav.history
+	if len(history.back) > 0 {
+		s.openRemote(history.back[len(history.back)-1], func(string) { history.Back(s.currentRemote) })
+	}
+}
+
+func (s *session) onRemoteForward() {
+	history := &s.remotePane.nav.history
+	if len(history.forward) > 0 {
+		s.openRemote(history.forward[len(history.forward)-1], func(string) { history.Forward(s.currentRemote) })
+	}
+}
+
+func (s *session) onRemoteUp() {
+	s.navigateRemote(path.Dir(s.currentRemote), true)
+}
@@ -0,0 +1,131 @@
+package main
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"reflect"
+	"testing"
+)
+
+func TestNavHistory(t *testing.T) {
+	var h navHistory
+
+	if _, ok := h.Back("/a"); ok {
+		t.Error("Back on empty history should fail")
+	}
+
+	h.Visit("/a")
+	h.Visit("/a/b")
+
+	prev, ok := h.Back("/a/b/c")
+	if !ok || prev != "/a/b" {
+		t.Errorf("Back returned %q, %v", prev, ok)
+	}
+
+	next, ok := h.Forward("/a/b")
+	if !ok || next != "/a/b/c" {
+		t.Errorf("Forward returned %q, %v", next, ok)
+	}
+
+	// Forward after Back restores both stacks
+	if prev, ok := h.Back("/a/b/c"); ok {
+		h.Forward(prev)
+	}
+	if !reflect.DeepEqual(h.back, []string{"/a", "/a/b"}) || len(h.forward) != 0 {
+		t.Errorf("Back then Forward should restore the history, got %+v", h)
+	}
+
+	h.Back("/a/b/c")
+	h.Visit("/a/b")
+	if _, ok := h.Forward("/x"); ok {
+		t.Error("Visiting a new directory should clear the forward history")
+	}
+}
+
+func TestRemoteBreadcrumbs(t *testing.T) {
+	got := remoteBreadcrumbs("/home/user/logs")
+	want := []breadcrumb{
+		{label: "/", path: "/"},
+		{label: "home", path: "/home"},
+		{label: "user", path: "/home/user"},
+		{label: "logs", path: "/home/user/logs"},
+	}
+	if !reflect.DeepEqual(got, want) {
+		t.Errorf("remoteBreadcrumbs returned %v, want %v", got, want)
+	}
+
+	if got := remoteBreadcrumbs("/"); len(got) != 1 {
+		t.Errorf("Root should have a single breadcrumb, got %v", got)
+	}
+}
+
+func TestSFTPApp_BackToMissingFolder(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping GUI test in short mode")
+	}
+	t.Setenv("HOME", t.TempDir())
+
+	app := newTestApp()
+	root := t.TempDir()
+	first, second := filepath.Join(root, "first"), filepath.Join(root, "second")
+	for _, dir := range []string{first, second} {
+		if err := os.Mkdir(dir, 0755); err != nil {
+			t.Fatal(err)
+		}
+	}
+	app.navigateLocal(first, true)
+	app.navigateLocal(second, true)
+	if err := os.Remove(first); err != nil {
+		t.Fatal(err)
+	}
+
+	app.onLocalBack()
+	if app.currentLocal != second {
+		t.Errorf("Back to a missing folder should stay in %s, got %s", second, app.currentLocal)
+	}
+	if history := app.localPane.nav.history; len(history.back) == 0 || history.back[len(history.back)-1] != first || len(history.forward) != 0 {
+		t.Errorf("A failed Back should keep the history entry, got %+v", history)
+	}
+	if app.localPane.nav.backBtn.Disabled() || !app.localPane.nav.forwardBtn.Disabled() {
+		t.Error("A failed Back should leave Back enabled and Forward disabled")
+	}
+}
+
+func TestSession_RemoteBackToMissingFolder(t *testing.T) {
+	if testing.Short() {
+		t.Skip("Skipping GUI test in short mode")
+	}
+	t.Setenv("HOME", t.TempDir())
+
+	app := newTestApp()
+	client, root := newTestClient(t)
+	s := app.newSession(client)
+	first, second := path.Join(root, "first"), path.Join(root, "second")
+	writeTestFiles(t, root, map[string]string{"first/a.txt": "a", "second/b.txt": "b"})
+	s.navigateRemote(first, true)
+	waitForRemoteListing(t, s)
+	s.navigateRemote(second, true)
+	if s.currentRemote != first {
+		t.Errorf("The directory should change once it was checked, got %s", s.currentRemote)
+	}
+	waitForRemoteListing(t, s)
+	if s.currentRemote != second {
+		t.Fatalf("Expected to be in %s, got %s", second, s.currentRemote)
+	}
+
+	if err := os.RemoveAll(first); err != nil {
+		t.Fatal(err)
+	}
+	s.onRemoteBack()
+	waitForRemoteListing(t, s)
+	if s.currentRemote != second {
+		t.Errorf("Back to a missing folder should stay in %s, got %s", second, s.currentRemote)
+	}
+	if history := s.remotePane.nav.history; len(history.back) == 0 || history.back[len(history.back)-1] != first || len(history.forward) != 0 {
+		t.Errorf("A failed Back should keep the history entry, got %+v", history)
+	}
+	if s.remotePane.nav.backBtn.Disabled() || !s.remotePane.nav.forwardBtn.Disabled() {
+		t.Error("A failed Back should leave Back enabled and Forward disabled")
+	}
+}
@@ -0,0 +1,511 @@
+package main
+
+import (
+	"fmt"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/layout"
+	"fyne.io/fyne/v2/widget"
+)
+
+// tableColumn describes a column of the file tables
+type tableColumn struct {
+	title  string
+	sortBy string
+	width  float32
+	value  func(fileEntry) string
+}
+
+var fileColumns = []tableColumn{
+	{title: "Name", sortBy: sortByName, width: 280, value: fileEntry.DisplayName},
+	{title: "Size", sortBy: sortBySize, width: 90, value: fileEntry.SizeText},
+	{title: "Modified", sortBy: sortByModified, width: 140, value: fileEntry.ModTimeText},
+	{title: "Permissions", sortBy: sortByPermissions, width: 110, value: func(e fileEntry) string { return e.Mode.String() }},
+	{title: "Owner", sortBy: sortByOwner, width: 110, value: func(e fileEntry) string { return e.Owner }},
+}
+
+// fileTable is a widget.Table that offers the keys it doesn't use itself
+// to the pane first, so shortcuts such as F2 work while it has focus
+type fileTable struct {
+	widget.Table
+	onTypedKey func(*fyne.KeyEvent) bool
+}
+
+func (t *fileTable) TypedKey(key *fyne.KeyEvent) {
+	if t.onTypedKey != nil && t.onTypedKey(key) {
+		return
+	}
+	t.Table.TypedKey(key)
+}
+
+// AcceptsTab keeps Tab from moving the focus away, so it can switch panes
+func (t *fileTable) AcceptsTab() bool {
+	return true
+}
+
+// Tapped selects the tapped cell and focuses the wrapping table rather
+// than the embedded one, so TypedKey above receives the key events
+func (t *fileTable) Tapped(e *fyne.PointEvent) {
+	t.Table.Tapped(e)
+	if c := fyne.CurrentApp().Driver().CanvasForObject(t); c != nil {
+		c.Focus(t)
+	}
+}
+
+// filePane holds the listing, selection and table of one side of the browser
+type filePane struct {
+	// all is the full directory listing, entries the filtered and sorted rows
+	all         []fileEntry
+	entries     []fileEntry
+	match       func(name string) bool
+	filter      *filterBar
+	sel         *fileSelection
+	sort        sortSetting
+	showHidden  bool
+	hiddenCheck *widget.Check
+	table       *fileTable
+	selLabel    *widget.Label
+	nav         *paneNav
+	drag        *dragTracker
+
+	// loading is set while the listing is still arriving, selectLoaded
+	// names an entry to select once it has
+	loading      bool
+	selectLoaded string
+
+	// renameRow is the row being renamed inline, or -1
+	renameRow   int
+	renameFocus bool
+
+	// onSettingsChanged is called after the user changes the sort order
+	// or the hidden files toggle
+	onSettingsChanged func(paneSettings)
+	// onDoubleClick is called with an entry clicked twice within 500ms
+	onDoubleClick func(fileEntry)
+	// onActivated is called when the user clicks into the pane
+	onActivated func()
+	// onSelectionChanged is called after the selection or the rows change
+	onSelectionChanged func()
+	// onRename is called when an inline rename is committed
+	onRename func(entry fileEntry, newName string)
+	// onKey is offered key presses while the table has focus and
+	// returns whether it handled them
+	onKey func(*fyne.KeyEvent) bool
+	// onContextMenu returns the right-click menu for the selected entries
+	onContextMenu func() *fyne.Menu
+}
+
+// newFilePane creates a pane using the given view settings. Entries
+// dragged out of the pane are reported to drag.
+func newFilePane(settings paneSettings, nav *paneNav, drag *dragTracker) *filePane {
+	pane := &filePane{
+		sel:        newFileSelection(),
+		sort:       settings.Sort,
+		showHidden: settings.ShowHidden,
+		selLabel:   widget.NewLabel(""),
+		nav:        nav,
+		drag:       drag,
+		renameRow:  -1,
+	}
+	pane.createTable()
+	pane.hiddenCheck = widget.NewCheck("Hidden", pane.setShowHidden)
+	pane.hiddenCheck.Checked = settings.ShowHidden
+	pane.filter = newFilterBar(func(match func(string) bool) {
+		pane.match = match
+		pane.applyView()
+	})
+	pane.updateSelectionLabel()
+	return pane
+}
+
+func (p *filePane) createTable() {
+	p.table = &fileTable{}
+	p.table.Length = func() (int, int) {
+		return len(p.entries), len(fileColumns)
+	}
+	p.table.CreateCell = func() fyne.CanvasObject {
+		return newFileCell(p)
+	}
+	p.table.UpdateCell = func(id widget.TableCellID, obj fyne.CanvasObject) {
+		p.updateCell(id, obj.(*fileCell))
+	}
+	p.table.onTypedKey = func(key *fyne.KeyEvent) bool {
+		return p.onKey != nil && p.onKey(key)
+	}
+	p.table.ExtendBaseWidget(p.table)
+
+	p.table.ShowHeaderRow = true
+	p.table.CreateHeader = func() fyne.CanvasObject {
+		btn := widget.NewButton("", nil)
+		btn.Alignment = widget.ButtonAlignLeading
+		btn.Importance = widget.LowImportance
+		return btn
+	}
+	p.table.UpdateHeader = func(id widget.TableCellID, obj fyne.CanvasObject) {
+		btn := obj.(*widget.Button)
+		column := fileColumns[id.Col]
+		text := column.title
+		if column.sortBy == p.sort.Column {
+			if p.sort.Ascending {
+				text += " ▲"
+			} else {
+				text += " ▼"
+			}
+		}
+		btn.SetText(text)
+		btn.OnTapped = func() { p.setSortColumn(column.sortBy) }
+	}
+	for i, column := range fileColumns {
+		p.table.SetColumnWidth(i, column.width)
+	}
+
+	var lastClickTime time.Time
+	lastClickRow := -1
+	p.table.OnSelected = func(id widget.TableCellID) {
+		// The table's own single cell selection is replaced by sel
+		p.table.Unselect(id)
+		if p.onActivated != nil {
+			p.onActivated()
+		}
+		p.sel.Click(id.Row, currentKeyModifiers())
+		p.table.Refresh()
+		p.selectionChanged()
+
+		now := time.Now()
+		if p.onDoubleClick != nil && id.Row == lastClickRow && now.Sub(lastClickTime) < 500*time.Millisecond {
+			if id.Row < len(p.entries) {
+				p.onDoubleClick(p.entries[id.Row])
+			}
+		}
+		lastClickTime = now
+		lastClickRow = id.Row
+	}
+}
+
+// updateCell shows the entry at id in cell
+func (p *filePane) updateCell(id widget.TableCellID, cell *fileCell) {
+	cell.row, cell.col = id.Row, id.Col
+	if id.Row >= len(p.entries) {
+		cell.row = -1
+		cell.label.SetText("")
+		return
+	}
+
+	entry := p.entries[id.Row]
+	cell.label.SetText(fileColumns[id.Col].value(entry))
+	cell.bg.FillColor = cellBackground(p, id.Row)
+	cell.bg.Refresh()
+
+	if id.Col != 0 || id.Row != p.renameRow {
+		cell.edit.onCancel = nil
+		cell.edit.Hide()
+		cell.label.Show()
+		return
+	}
+
+	// Inline rename editor
+	cell.label.Hide()
+	cell.edit.OnSubmitted = func(text string) { p.finishRename(text) }
+	if p.renameFocus {
+		p.renameFocus = false
+		cell.edit.SetText(entry.Name)
+		cell.edit.Show()
+		if c := fyne.CurrentApp().Driver().CanvasForObject(p.table); c != nil {
+			c.Focus(cell.edit)
+		}
+	}
+	cell.edit.onCancel = p.cancelRename
+	cell.edit.Show()
+}
+
+// showContextMenu pops up the context menu of row at pos. A row outside
+// the selection becomes the only selected entry first.
+func (p *filePane) showContextMenu(row int, pos fyne.Position) {
+	if p.onContextMenu == nil || row < 0 || row >= len(p.entries) {
+		return
+	}
+	if p.onActivated != nil {
+		p.onActivated()
+	}
+	if !p.sel.IsSelected(row) {
+		p.sel.Set(row)
+		p.table.Refresh()
+		p.selectionChanged()
+	}
+
+	if c := fyne.CurrentApp().Driver().CanvasForObject(p.table); c != nil {
+		widget.ShowPopUpMenuAtPosition(p.onContextMenu(), c, pos)
+	}
+}
+
+// startRename shows the inline rename editor for the single selected entry
+func (p *filePane) startRename() bool {
+	ids := p.sel.Indices()
+	if len(ids) != 1 || ids[0] >= len(p.entries) || p.entries[ids[0]].IsParent() {
+		return false
+	}
+	p.renameRow = ids[0]
+	p.renameFocus = true
+	p.table.ScrollTo(widget.TableCellID{Row: p.renameRow, Col: 0})
+	p.table.Refresh()
+	return true
+}
+
+// finishRename commits the inline rename
+func (p *filePane) finishRename(newName string) {
+	row := p.renameRow
+	if row < 0 || row >= len(p.entries) {
+		return
+	}
+	p.renameRow = -1
+	p.table.Refresh()
+
+	entry := p.entries[row]
+	if newName != "" && newName != entry.Name && p.onRename != nil {
+		p.onRename(entry, newName)
+	}
+}
+
+// cancelRename hides the inline rename editor without renaming
+func (p *filePane) cancelRename() {
+	if p.renameRow < 0 {
+		return
+	}
+	p.renameRow = -1
+	p.table.Refresh()
+}
+
+// setEntries replaces the listing. A ".." entry is added when the
+// directory has a parent.
+func (p *filePane) setEntries(entries []fileEntry, hasParent bool) {
+	if hasParent {
+		entries = append([]fileEntry{parentDirEntry}, entries...)
+	}
+	p.all = entries
+	p.applyView()
+}
+
+// addEntries adds entries to a listing that is loading. They are shown
+// after the rows already there in the order they arrived, which keeps
+// the selection; the rows are sorted once the listing has finished.
+func (p *filePane) addEntries(entries []fileEntry) {
+	if len(entries) == 0 {
+		return
+	}
+	p.all = append(p.all, entries...)
+	for _, entry := range entries {
+		if p.visible(entry) {
+			p.entries = append(p.entries, entry)
+		}
+	}
+	p.table.Refresh()
+	p.selectionChanged()
+}
+
+// setLoading shows whether the listing is still arriving. Once it has,
+// the rows are sorted and the entry passed to selectWhenLoaded is
+// selected.
+func (p *filePane) setLoading(loading bool) {
+	wasLoading := p.loading
+	p.loading = loading
+	if wasLoading && !loading {
+		selected := p.selectedEntries()
+		sortEntries(p.entries, p.sort)
+		p.reselect(selected)
+	}
+	if !loading && p.selectLoaded != "" {
+		name := p.selectLoaded
+		p.selectLoaded = ""
+		p.selectName(name)
+		return
+	}
+	p.updateSelectionLabel()
+}
+
+// selectWhenLoaded selects the named entry, waiting for the listing if
+// it is still loading
+func (p *filePane) selectWhenLoaded(name string) {
+	if p.loading {
+		p.selectLoaded = name
+		return
+	}
+	p.selectName(name)
+}
+
+// markStale flags the named entries as changed on the server without
+// rebuilding the rows, so the selection is kept
+func (p *filePane) markStale(names map[string]bool) {
+	for i := range p.all {
+		p.all[i].Stale = names[p.all[i].Name]
+	}
+	for i := range p.entries {
+		p.entries[i].Stale = names[p.entries[i].Name]
+	}
+	p.table.Refresh()
+}
+
+// visible reports whether entry passes the hidden files toggle and the
+// filter
+func (p *filePane) visible(entry fileEntry) bool {
+	if entry.IsParent() {
+		return true
+	}
+	if entry.Hidden && !p.showHidden {
+		return false
+	}
+	return p.match == nil || p.match(entry.Name)
+}
+
+// applyView rebuilds the visible rows from the listing, the filter and
+// the sort order. The selection is cleared since its row indices no
+// longer apply.
+func (p *filePane) applyView() {
+	entries := make([]fileEntry, 0, len(p.all))
+	for _, entry := range p.all {
+		if p.visible(entry) {
+			entries = append(entries, entry)
+		}
+	}
+	sortEntries(entries, p.sort)
+	p.entries = entries
+	p.renameRow = -1
+	p.sel.Clear()
+	p.table.Refresh()
+	p.selectionChanged()
+}
+
+// selectName selects the row of the named entry and scrolls to it
+func (p *filePane) selectName(name string) {
+	for i, entry := range p.entries {
+		if entry.Name == name {
+			p.sel.Set(i)
+			p.table.ScrollTo(widget.TableCellID{Row: i, Col: 0})
+			break
+		}
+	}
+	p.table.Refresh()
+	p.selectionChanged()
+}
+
+// setSortColumn sorts by column, reversing the order if it is already the sort column
+func (p *filePane) setSortColumn(column string) {
+	if p.sort.Column == column {
+		p.sort.Ascending = !p.sort.Ascending
+	} else {
+		p.sort = sortSetting{Column: column, Ascending: true}
+	}
+
+	selected := p.selectedEntries()
+	sortEntries(p.entries, p.sort)
+	p.reselect(selected)
+	p.settingsChanged()
+}
+
+// setShowHidden shows or hides dotfiles and OS-hidden files
+func (p *filePane) setShowHidden(show bool) {
+	if p.showHidden == show {
+		return
+	}
+	p.showHidden = show
+	p.applyView()
+	p.settingsChanged()
+}
+
+// toggleHidden flips the hidden files toggle
+func (p *filePane) toggleHidden() {
+	p.hiddenCheck.SetChecked(!p.showHidden)
+}
+
+func (p *filePane) settingsChanged() {
+	if p.onSettingsChanged != nil {
+		p.onSettingsChanged(paneSettings{Sort: p.sort, ShowHidden: p.showHidden})
+	}
+}
+
+// reselect selects the rows holding the given entries
+func (p *filePane) reselect(entries []fileEntry) {
+	names := make(map[string]bool, len(entries))
+	for _, entry := range entries {
+		names[entry.Name] = true
+	}
+	p.sel.Clear()
+	for i, entry := range p.entries {
+		if names[entry.Name] {
+			p.sel.Toggle(i)
+		}
+	}
+	p.table.Refresh()
+	p.selectionChanged()
+}
+
+// clear empties the listing
+func (p *filePane) clear() {
+	p.setEntries(nil, false)
+}
+
+// selectedEntries returns the selected entries, excluding ".."
+func (p *filePane) selectedEntries() []fileEntry {
+	var entries []fileEntry
+	for _, id := range p.sel.Indices() {
+		if id < len(p.entries) && !p.entries[id].IsParent() {
+			entries = append(entries, p.entries[id])
+		}
+	}
+	return entries
+}
+
+// selectedNames returns the names of the selected entries, excluding ".."
+func (p *filePane) selectedNames() []string {
+	var names []string
+	for _, entry := range p.selectedEntries() {
+		names = append(names, entry.Name)
+	}
+	return names
+}
+
+// selectionBar creates the select all / invert / clear controls
+func (p *filePane) selectionBar() fyne.CanvasObject {
+	apply := func(change func()) func() {
+		return func() {
+			change()
+			p.table.Refresh()
+			p.selectionChanged()
+		}
+	}
+
+	return container.NewHBox(
+		widget.NewButton("All", apply(func() { p.sel.SelectAll(len(p.entries)) })),
+		widget.NewButton("Invert", apply(func() { p.sel.Invert(len(p.entries)) })),
+		widget.NewButton("None", apply(p.sel.Clear)),
+		p.hiddenCheck,
+		layout.NewSpacer(),
+		p.selLabel,
+	)
+}
+
+// selectionChanged updates the selection label and notifies onSelectionChanged
+func (p *filePane) selectionChanged() {
+	p.updateSelectionLabel()
+	if p.onSelectionChanged != nil {
+		p.onSelectionChanged()
+	}
+}
+
+// updateSelectionLabel shows how many entries are selected
+func (p *filePane) updateSelectionLabel() {
+	if p.loading && p.sel.Count() == 0 {
+		p.selLabel.SetText(fmt.Sprintf("Loading... %d items", len(p.all)))
+		return
+	}
+	if p.sel.Count() == 0 {
+		if len(p.entries) < len(p.all) {
+			p.selLabel.SetText(fmt.Sprintf("%d of %d items shown", len(p.entries), len(p.all)))
+			return
+		}
+		p.selLabel.SetText(fmt.Sprintf("%d items", len(p.entries)))
+		return
+	}
+	p.selLabel.SetText(fmt.Sprintf("%d of %d selected", p.sel.Count(), len(p.entries)))
+}
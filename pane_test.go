@@ -0,0 +1,119 @@
+package main
+
+import (
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+)
+
+func newTestPane(settings paneSettings) *filePane {
+	return newFilePane(settings, newPaneNav(func() {}, func() {}, func() {}), newDragTracker())
+}
+
+func TestFilePane_HiddenFiles(t *testing.T) {
+	test.NewApp()
+	pane := newTestPane(paneSettings{Sort: defaultSort})
+
+	var saved paneSettings
+	pane.onSettingsChanged = func(settings paneSettings) { saved = settings }
+
+	pane.setEntries([]fileEntry{
+		{Name: ".bashrc", Hidden: true},
+		{Name: "desktop.ini", Hidden: true},
+		{Name: "notes.txt"},
+	}, true)
+
+	if len(pane.entries) != 2 {
+		t.Fatalf("Expected .. and notes.txt to be shown, got %v", pane.entries)
+	}
+
+	pane.toggleHidden()
+	if len(pane.entries) != 4 {
+		t.Errorf("Expected all entries after showing hidden files, got %d", len(pane.entries))
+	}
+	if !saved.ShowHidden {
+		t.Error("Toggling hidden files should report the new setting")
+	}
+}
+
+func TestFilePane_FilterAndSelection(t *testing.T) {
+	test.NewApp()
+	pane := newTestPane(paneSettings{Sort: defaultSort, ShowHidden: true})
+	pane.setEntries([]fileEntry{
+		{Name: "b.log"},
+		{Name: "a.log"},
+		{Name: "readme.md"},
+	}, true)
+
+	pane.filter.mode.SetSelected(matchGlob)
+	pane.filter.entry.SetText("*.log")
+	if len(pane.entries) != 3 {
+		t.Fatalf("Expected .. and two logs, got %v", pane.entries)
+	}
+
+	pane.sel.SelectAll(len(pane.entries))
+	if got := pane.selectedNames(); len(got) != 2 || got[0] != "a.log" || got[1] != "b.log" {
+		t.Errorf("Selection should skip .. and follow sort order, got %v", got)
+	}
+}
+
+func TestFilePane_InlineRename(t *testing.T) {
+	test.NewApp()
+	pane := newTestPane(paneSettings{Sort: defaultSort})
+	pane.setEntries([]fileEntry{{Name: "a.txt"}, {Name: "b.txt"}}, true)
+
+	var renamed, newName string
+	pane.onRename = func(entry fileEntry, name string) {
+		renamed, newName = entry.Name, name
+	}
+
+	pane.sel.Set(0)
+	if pane.startRename() {
+		t.Error("The .. entry should not be renamable")
+	}
+
+	pane.sel.SelectAll(len(pane.entries))
+	if pane.startRename() {
+		t.Error("Inline rename needs a single selected entry")
+	}
+
+	pane.sel.Set(2)
+	if !pane.startRename() {
+		t.Fatal("startRename failed for a single selected entry")
+	}
+	pane.finishRename("c.txt")
+	if renamed != "b.txt" || newName != "c.txt" {
+		t.Errorf("Expected b.txt to be renamed to c.txt, got %q → %q", renamed, newName)
+	}
+	if pane.renameRow != -1 {
+		t.Error("The rename editor should be closed after committing")
+	}
+}
+
+func TestFilePane_LoadingBatches(t *testing.T) {
+	test.NewApp()
+	pane := newTestPane(paneSettings{Sort: defaultSort})
+
+	pane.setEntries(nil, true)
+	pane.setLoading(true)
+	pane.addEntries([]fileEntry{{Name: "c.txt"}, {Name: "a.txt"}})
+	pane.sel.Set(1)
+	pane.addEntries([]fileEntry{{Name: "b.txt"}})
+
+	// Batches are appended as they arrive so the selection stays put
+	if names := pane.selectedNames(); len(names) != 1 || names[0] != "c.txt" {
+		t.Errorf("Expected c.txt to stay selected while loading, got %v", names)
+	}
+
+	pane.setLoading(false)
+	var names []string
+	for _, entry := range pane.entries {
+		names = append(names, entry.Name)
+	}
+	if len(names) != 4 || names[1] != "a.txt" || names[2] != "b.txt" || names[3] != "c.txt" {
+		t.Errorf("Expected the rows sorted once loaded, got %v", names)
+	}
+	if selected := pane.selectedNames(); len(selected) != 1 || selected[0] != "c.txt" {
+		t.Errorf("Expected c.txt to stay selected after sorting, got %v", selected)
+	}
+}
@@ -0,0 +1,302 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"fmt"
+	_ "image/gif" // canvas decodes PNG and JPEG itself
+	"io"
+	"net/http"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+	"unicode/utf8"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/canvas"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Preview size limits. Text and hex dumps show the start of larger files.
+const (
+	previewTextLimit  = 256 << 10
+	previewHexLimit   = 4 << 10
+	previewImageLimit = 20 << 20
+)
+
+// Kinds of previews
+const (
+	previewText   = "text"
+	previewImage  = "image"
+	previewBinary = "binary"
+)
+
+// previewKind decides how to show a file from the start of its content
+func previewKind(head []byte) string {
+	switch http.DetectContentType(head) {
+	case "image/png", "image/jpeg", "image/gif":
+		return previewImage
+	}
+	if looksLikeText(head) {
+		return previewText
+	}
+	return previewBinary
+}
+
+// looksLikeText reports whether data is UTF-8 without NUL bytes. A rune
+// cut off at the end of data is allowed.
+func looksLikeText(data []byte) bool {
+	if bytes.IndexByte(data, 0) >= 0 {
+		return false
+	}
+	for len(data) > 0 {
+		r, size := utf8.DecodeRune(data)
+		if r == utf8.RuneError && size <= 1 {
+			return len(data) < utf8.UTFMax && !utf8.FullRune(data)
+		}
+		data = data[size:]
+	}
+	return true
+}
+
+// commentPrefixes maps file extensions to their line comment markers
+var commentPrefixes = map[string][]string{
+	".go": {"//"}, ".c": {"//"}, ".h": {"//"}, ".cpp": {"//"}, ".java": {"//"},
+	".js": {"//"}, ".ts": {"//"}, ".rs": {"//"}, ".swift": {"//"}, ".kt": {"//"},
+	".sh": {"#"}, ".bash": {"#"}, ".py": {"#"}, ".rb": {"#"}, ".pl": {"#"},
+	".yaml": {"#"}, ".yml": {"#"}, ".toml": {"#"}, ".conf": {"#"}, ".cfg": {"#", ";"},
+	".ini": {";", "#"}, ".env": {"#"}, ".properties": {"#", "!"}, ".sql": {"--"},
+	".lua": {"--"}, ".service": {"#", ";"},
+}
+
+// highlightText splits text into monospace lines, dimming comments and
+// emphasizing section headers of config files
+func highlightText(name, text string) []widget.RichTextSegment {
+	ext := strings.ToLower(filepath.Ext(name))
+	prefixes, ok := commentPrefixes[ext]
+	if !ok {
+		// Extensionless scripts and dotfiles mostly use # comments
+		prefixes = []string{"#"}
+	}
+	sections := ext == ".ini" || ext == ".toml" || ext == ".cfg" || ext == ".conf" || ext == ".service"
+
+	lines := strings.Split(strings.TrimSuffix(text, "\n"), "\n")
+	segments := make([]widget.RichTextSegment, 0, len(lines))
+	for _, line := range lines {
+		style := widget.RichTextStyleCodeBlock
+		trimmed := strings.TrimSpace(line)
+		for _, prefix := range prefixes {
+			if strings.HasPrefix(trimmed, prefix) {
+				style.ColorName = theme.ColorNamePlaceHolder
+			}
+		}
+		if sections && strings.HasPrefix(trimmed, "[") && strings.HasSuffix(trimmed, "]") {
+			style.ColorName = theme.ColorNamePrimary
+			style.TextStyle.Bold = true
+		}
+		segments = append(segments, &widget.TextSegment{Text: line, Style: style})
+	}
+	return segments
+}
+
+// previewPanel shows the content of the selected local or remote file
+type previewPanel struct {
+	title   *widget.Label
+	info    *widget.Label
+	content *fyne.Container
+	card    fyne.CanvasObject
+
+	// cancel stops the read of the latest request. Starting another
+	// request cancels it, so a stale load is neither finished nor shown.
+	cancel context.CancelFunc
+}
+
+func newPreviewPanel() *previewPanel {
+	p := &previewPanel{
+		title:   widget.NewLabelWithStyle("", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		info:    widget.NewLabel(""),
+		content: container.NewStack(),
+	}
+	p.title.Truncation = fyne.TextTruncateEllipsis
+	p.info.Truncation = fyne.TextTruncateEllipsis
+	p.card = widget.NewCard("Preview", "", container.NewBorder(
+		container.NewVBox(p.title, p.info), nil, nil, nil, p.content))
+	p.showMessage("Select a file to preview it")
+	return p
+}
+
+// next cancels the previous request and returns the context of a new one
+func (p *previewPanel) next() context.Context {
+	if p.cancel != nil {
+		p.cancel()
+	}
+	ctx, cancel := context.WithCancel(context.Background())
+	p.cancel = cancel
+	return ctx
+}
+
+func (p *previewPanel) showMessage(message string) {
+	p.title.SetText("")
+	p.info.SetText("")
+	p.content.Objects = []fyne.CanvasObject{widget.NewLabel(message)}
+	p.content.Refresh()
+}
+
+// show renders data read from the start of a file of the given size
+func (p *previewPanel) show(name string, size int64, data []byte) {
+	kind := previewKind(data)
+	if kind == previewImage && int64(len(data)) < size {
+		// Too large to load, fall back to the hex dump of its start
+		kind = previewBinary
+	}
+
+	var object fyne.CanvasObject
+	info := humanSize(size)
+	switch kind {
+	case previewImage:
+		img := canvas.NewImageFromReader(bytes.NewReader(data), name)
+		img.FillMode = canvas.ImageFillContain
+		img.SetMinSize(fyne.NewSize(100, 100))
+		object = img
+		info += ", image"
+	case previewText:
+		text := widget.NewRichText(highlightText(name, string(data))...)
+		object = container.NewScroll(text)
+		if int64(len(data)) < size {
+			info += fmt.Sprintf(", showing the first %s", humanSize(int64(len(data))))
+		}
+	default:
+		if len(data) > previewHexLimit {
+			data = data[:previewHexLimit]
+		}
+		dump := widget.NewRichText(&widget.TextSegment{Text: hex.Dump(data), Style: widget.RichTextStyleCodeBlock})
+		object = container.NewScroll(dump)
+		info += ", binary"
+		if int64(len(data)) < size {
+			info += fmt.Sprintf(", showing the first %s", humanSize(int64(len(data))))
+		}
+	}
+
+	p.title.SetText(name)
+	p.info.SetText(info)
+	p.content.Objects = []fyne.CanvasObject{object}
+	p.content.Refresh()
+}
+
+// readPreview reads the start of a file, enough to preview it. Images are
+// read completely up to previewImageLimit.
+func readPreview(r io.Reader, size int64) ([]byte, error) {
+	limit := int64(previewTextLimit)
+	if size <= previewImageLimit {
+		head := make([]byte, 512)
+		n, err := io.ReadFull(r, head)
+		if err != nil && err != io.ErrUnexpectedEOF && err != io.EOF {
+			return nil, err
+		}
+		head = head[:n]
+		if previewKind(head) == previewImage {
+			limit = previewImageLimit
+		}
+		rest, err := io.ReadAll(io.LimitReader(r, limit-int64(n)))
+		return append(head, rest...), err
+	}
+	return io.ReadAll(io.LimitReader(r, limit))
+}
+
+// updatePreview previews the single selected file of the active pane
+func (s *session) updatePreview() {
+	if s.preview == nil || s.activePane == nil || !s.settings.ShowPreview {
+		return
+	}
+
+	pane := s.activePane
+	entries := pane.selectedEntries()
+	if len(entries) != 1 {
+		s.preview.next()
+		s.preview.showMessage("Select a file to preview it")
+		return
+	}
+	entry := entries[0]
+	if entry.IsDir || entry.LinkDir {
+		s.preview.next()
+		s.preview.showMessage(fmt.Sprintf("%s is a folder", entry.Name))
+		return
+	}
+
+	remote := pane == s.remotePane
+	var open func() (io.ReadCloser, int64, error)
+	if remote {
+		if !s.client.IsConnected() {
+			return
+		}
+		remotePath := path.Join(s.currentRemote, entry.Name)
+		open = func() (io.ReadCloser, int64, error) {
+			f, err := s.client.sftpClient.Open(remotePath)
+			if err != nil {
+				return nil, 0, err
+			}
+			info, err := f.Stat()
+			if err != nil {
+				f.Close()
+				return nil, 0, err
+			}
+			return f, info.Size(), nil
+		}
+	} else {
+		localPath := filepath.Join(s.currentLocal, entry.Name)
+		open = func() (io.ReadCloser, int64, error) {
+			f, err := os.Open(localPath)
+			if err != nil {
+				return nil, 0, err
+			}
+			info, err := f.Stat()
+			if err != nil {
+				f.Close()
+				return nil, 0, err
+			}
+			return f, info.Size(), nil
+		}
+	}
+
+	ctx := s.preview.next()
+	s.preview.showMessage(fmt.Sprintf("Loading %s...", entry.Name))
+	go func() {
+		f, size, err := open()
+		var data []byte
+		if err == nil {
+			// Selecting another file closes f, which ends the read
+			stop := closeOnCancel(ctx, f)
+			data, err = readPreview(f, size)
+			stop()
+			f.Close()
+		}
+		s.do(func() {
+			if ctx.Err() != nil {
+				return
+			}
+			if err != nil {
+				s.preview.showMessage(fmt.Sprintf("Cannot preview %s: %v", entry.Name, err))
+				return
+			}
+			s.preview.show(entry.Name, size, data)
+		})
+	}()
+}
+
+// setPreviewVisible shows or hides the preview panel next to the file panes
+func (app *SFTPApp) setPreviewVisible(show bool) {
+	app.settings.ShowPreview = show
+	if show {
+		app.browserArea.Objects = []fyne.CanvasObject{app.previewSplit}
+		if app.current != nil {
+			app.current.updatePreview()
+		}
+	} else {
+		app.browserArea.Objects = []fyne.CanvasObject{app.browserPanes}
+	}
+	app.browserArea.Refresh()
+}
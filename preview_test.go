@@ -0,0 +1,85 @@
+package main
+
+import (
+	"bytes"
+	"image"
+	"image/png"
+	"strings"
+	"testing"
+
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+func TestPreviewKind(t *testing.T) {
+	var buf bytes.Buffer
+	png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 2, 2)))
+
+	tests := []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{"png", buf.Bytes(), previewImage},
+		{"text", []byte("key = value\n"), previewText},
+		{"utf8", []byte("grüße\n"), previewText},
+		{"cut rune", []byte("gr\xc3"), previewText},
+		{"nul", []byte("abc\x00def"), previewBinary},
+		{"invalid", []byte("\xff\xfe\xfd\xfcabcdef"), previewBinary},
+	}
+	for _, tt := range tests {
+		if got := previewKind(tt.data); got != tt.want {
+			t.Errorf("%s: previewKind = %s, want %s", tt.name, got, tt.want)
+		}
+	}
+}
+
+func TestReadPreview(t *testing.T) {
+	text := strings.Repeat("x", previewTextLimit+100)
+	data, err := readPreview(strings.NewReader(text), int64(len(text)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(data) != previewTextLimit {
+		t.Errorf("Expected text capped at %d bytes, got %d", previewTextLimit, len(data))
+	}
+
+	var buf bytes.Buffer
+	png.Encode(&buf, image.NewRGBA(image.Rect(0, 0, 400, 400)))
+	data, _ = readPreview(bytes.NewReader(buf.Bytes()), int64(buf.Len()))
+	if !bytes.Equal(data, buf.Bytes()) {
+		t.Error("Expected images to be read completely")
+	}
+}
+
+func TestHighlightText(t *testing.T) {
+	segments := highlightText("app.ini", "[main]\n; comment\nkey = value\n")
+	if len(segments) != 3 {
+		t.Fatalf("Expected 3 lines, got %d", len(segments))
+	}
+	styles := make([]widget.RichTextStyle, len(segments))
+	for i, segment := range segments {
+		styles[i] = segment.(*widget.TextSegment).Style
+	}
+	if styles[0].ColorName != theme.ColorNamePrimary {
+		t.Error("Expected the section header to be highlighted")
+	}
+	if styles[1].ColorName != theme.ColorNamePlaceHolder {
+		t.Error("Expected the comment to be dimmed")
+	}
+	if styles[2].ColorName != theme.ColorNameForeground || !styles[2].TextStyle.Monospace {
+		t.Error("Expected plain monospace text")
+	}
+}
+
+func TestPreviewPanel_Next(t *testing.T) {
+	p := &previewPanel{}
+	first := p.next()
+	second := p.next()
+	if first.Err() == nil {
+		t.Error("A new request should cancel the read of the previous one")
+	}
+	if second.Err() != nil {
+		t.Error("The latest request should not be cancelled")
+	}
+}
@@ -0,0 +1,611 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"os"
+	"path"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/pkg/sftp"
+)
+
+// permissionBits lists the rwx bits in owner, group, other order
+var permissionBits = [3][3]os.FileMode{
+	{0400, 0200, 0100},
+	{0040, 0020, 0010},
+	{0004, 0002, 0001},
+}
+
+// specialBits lists the setuid, setgid and sticky bits with their octal value
+var specialBits = []struct {
+	mode  os.FileMode
+	octal uint32
+	label string
+}{
+	{os.ModeSetuid, 04000, "setuid"},
+	{os.ModeSetgid, 02000, "setgid"},
+	{os.ModeSticky, 01000, "sticky"},
+}
+
+// modeToOctal formats the permission and special bits of mode as four octal digits
+func modeToOctal(mode os.FileMode) string {
+	value := uint32(mode.Perm())
+	for _, bit := range specialBits {
+		if mode&bit.mode != 0 {
+			value |= bit.octal
+		}
+	}
+	return fmt.Sprintf("%04o", value)
+}
+
+// octalToMode parses an octal mode such as "755" or "4755"
+func octalToMode(text string) (os.FileMode, error) {
+	value, err := strconv.ParseUint(strings.TrimSpace(text), 8, 32)
+	if err != nil || value > 07777 {
+		return 0, fmt.Errorf("invalid octal mode: %q", text)
+	}
+
+	mode := os.FileMode(value) & os.ModePerm
+	for _, bit := range specialBits {
+		if uint32(value)&bit.octal != 0 {
+			mode |= bit.mode
+		}
+	}
+	return mode, nil
+}
+
+// Lstat returns the attributes of a remote path without following symlinks
+func (c *SFTPGUIClient) Lstat(p string) (os.FileInfo, error) {
+	if !c.IsConnected() {
+		return nil, fmt.Errorf("not connected")
+	}
+
+	return c.sftpClient.Lstat(p)
+}
+
+// Chmod changes the mode of a remote path, and of everything below it
+// when recursive is set
+func (c *SFTPGUIClient) Chmod(p string, mode os.FileMode, recursive bool) error {
+	return c.ChmodContext(context.Background(), p, mode, recursive)
+}
+
+// ChmodContext is Chmod stopping once ctx is done
+func (c *SFTPGUIClient) ChmodContext(ctx context.Context, p string, mode os.FileMode, recursive bool) error {
+	return c.applyRecursive(ctx, p, recursive, func(target string, info os.FileInfo) error {
+		if recursive {
+			return c.sftpClient.Chmod(target, treeMode(mode, info.Mode()))
+		}
+		return c.sftpClient.Chmod(target, mode)
+	})
+}
+
+// Chown changes the owner and group of a remote path, and of everything
+// below it when recursive is set
+func (c *SFTPGUIClient) Chown(p string, uid, gid int, recursive bool) error {
+	return c.ChownContext(context.Background(), p, uid, gid, recursive)
+}
+
+// ChownContext is Chown stopping once ctx is done
+func (c *SFTPGUIClient) ChownContext(ctx context.Context, p string, uid, gid int, recursive bool) error {
+	return c.applyRecursive(ctx, p, recursive, func(target string, _ os.FileInfo) error {
+		return c.sftpClient.Chown(target, uid, gid)
+	})
+}
+
+// treeMode is the mode a recursive chmod gives an entry whose mode is
+// current. Like the X of chmod, the execute bits of mode only go to
+// folders and to files that are already executable.
+func treeMode(mode, current os.FileMode) os.FileMode {
+	if current.IsDir() || current&0111 != 0 {
+		return mode
+	}
+	return mode &^ 0111
+}
+
+// applyRecursive calls apply for p and, when recursive is set, for every
+// entry below it, along with the attributes of the entry. Symlinks inside
+// the tree are left alone since the server would apply the change to
+// their targets. All entries are attempted and the failures are reported
+// together, unless ctx is done first.
+func (c *SFTPGUIClient) applyRecursive(ctx context.Context, p string, recursive bool, apply func(string, os.FileInfo) error) error {
+	if !c.IsConnected() {
+		return fmt.Errorf("not connected")
+	}
+
+	if !recursive {
+		info, err := c.sftpClient.Lstat(p)
+		if err != nil {
+			return err
+		}
+		return apply(p, info)
+	}
+
+	var failed []string
+	var firstErr error
+	total := 0
+	walker := c.sftpClient.Walk(p)
+	for walker.Step() {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		err := walker.Err()
+		if err == nil {
+			if walker.Path() != p && walker.Stat().Mode()&os.ModeSymlink != 0 {
+				continue
+			}
+			err = apply(walker.Path(), walker.Stat())
+		}
+		total++
+		if err != nil {
+			failed = append(failed, walker.Path())
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("%d of %d entries failed, first %s: %v", len(failed), total, failed[0], firstErr)
+	}
+	return nil
+}
+
+// propertiesTarget is the local or remote entry shown in the properties dialog
+type propertiesTarget struct {
+	path       string
+	info       os.FileInfo
+	uid, gid   int // -1 when unknown
+	accessed   time.Time
+	linkTarget string
+
+	chmod   func(ctx context.Context, mode os.FileMode, recursive bool) error // nil when the mode cannot be changed
+	chown   func(ctx context.Context, uid, gid int, recursive bool) error     // nil when ownership cannot be changed
+	refresh func()
+}
+
+// onProperties shows the properties of the single selected entry of the active pane
+func (s *session) onProperties() {
+	pane := s.activePane
+	if pane == s.remotePane && !s.client.IsConnected() {
+		return
+	}
+
+	entries := pane.selectedEntries()
+	if len(entries) != 1 {
+		s.showError("Please select a single entry")
+		return
+	}
+
+	if pane == s.localPane {
+		target, err := s.localPropertiesTarget(filepath.Join(s.currentLocal, entries[0].Name))
+		if err != nil {
+			s.showError(fmt.Sprintf("Cannot read properties: %v", err))
+			return
+		}
+		s.showProperties(target)
+		return
+	}
+
+	// The remote attributes are read in the background
+	remotePath := path.Join(s.currentRemote, entries[0].Name)
+	followLinks := s.settings.FollowLinks
+	ctx, done := s.startOperation()
+	go func() {
+		defer done()
+		target, err := s.remotePropertiesTarget(remotePath, followLinks)
+		switch {
+		case ctx.Err() != nil:
+			s.logMessage(fmt.Sprintf("Reading properties of %s cancelled", remotePath))
+		case err != nil:
+			s.showError(fmt.Sprintf("Cannot read properties: %v", err))
+		default:
+			s.do(func() { s.showProperties(target) })
+		}
+	}()
+}
+
+// remotePropertiesTarget describes a remote entry. Symlinks describe
+// their target when followLinks is set, and the link itself otherwise.
+// It talks to the server, so it runs in the background.
+func (s *session) remotePropertiesTarget(remotePath string, followLinks bool) (propertiesTarget, error) {
+	info, err := s.client.Lstat(remotePath)
+	if err != nil {
+		return propertiesTarget{}, err
+	}
+
+	target := propertiesTarget{
+		path: remotePath,
+		info: info,
+		uid:  -1,
+		gid:  -1,
+	}
+	target.refresh = func() {
+		s.remoteChanged(remotePath)
+		s.updateRemoteFiles()
+	}
+	editable := true
+	if info.Mode()&os.ModeSymlink != 0 {
+		target.linkTarget, _ = s.client.ReadLink(remotePath)
+		// SFTP can only change attributes through the link, so the link
+		// itself is read-only
+		editable = false
+		if followLinks {
+			if resolved, err := s.client.sftpClient.Stat(remotePath); err == nil {
+				target.info, editable = resolved, true
+			}
+		}
+	}
+	if stat, ok := target.info.Sys().(*sftp.FileStat); ok {
+		target.uid, target.gid = int(stat.UID), int(stat.GID)
+		target.accessed = time.Unix(int64(stat.Atime), 0)
+	}
+	if editable {
+		target.chmod = func(ctx context.Context, mode os.FileMode, recursive bool) error {
+			return s.client.ChmodContext(ctx, remotePath, mode, recursive)
+		}
+		target.chown = func(ctx context.Context, uid, gid int, recursive bool) error {
+			return s.client.ChownContext(ctx, remotePath, uid, gid, recursive)
+		}
+	}
+	return target, nil
+}
+
+// localPropertiesTarget describes a local entry. Symlinks describe
+// their target when links are followed, and the link itself otherwise.
+func (app *SFTPApp) localPropertiesTarget(localPath string) (propertiesTarget, error) {
+	info, err := os.Lstat(localPath)
+	if err != nil {
+		return propertiesTarget{}, err
+	}
+
+	target := propertiesTarget{
+		path:    localPath,
+		info:    info,
+		refresh: app.updateLocalFiles,
+	}
+	chown := os.Chown
+	isLink := info.Mode()&os.ModeSymlink != 0
+	if isLink {
+		target.linkTarget, _ = os.Readlink(localPath)
+		chown = os.Lchown
+		if app.settings.FollowLinks {
+			if resolved, err := os.Stat(localPath); err == nil {
+				target.info, isLink, chown = resolved, false, os.Chown
+			}
+		}
+	}
+
+	// Links have no permissions of their own
+	if !isLink {
+		target.chmod = func(ctx context.Context, mode os.FileMode, recursive bool) error {
+			return applyLocalRecursive(ctx, localPath, recursive, func(p string, info os.FileInfo) error {
+				if recursive {
+					return os.Chmod(p, treeMode(mode, info.Mode()))
+				}
+				return os.Chmod(p, mode)
+			})
+		}
+	}
+	var ok bool
+	if target.uid, target.gid, ok = localIDs(target.info); ok {
+		target.chown = func(ctx context.Context, uid, gid int, recursive bool) error {
+			return applyLocalRecursive(ctx, localPath, recursive, func(p string, _ os.FileInfo) error {
+				return chown(p, uid, gid)
+			})
+		}
+	}
+	return target, nil
+}
+
+// applyLocalRecursive is the local counterpart of applyRecursive
+func applyLocalRecursive(ctx context.Context, root string, recursive bool, apply func(string, os.FileInfo) error) error {
+	if !recursive {
+		info, err := os.Lstat(root)
+		if err != nil {
+			return err
+		}
+		return apply(root, info)
+	}
+
+	var failed []string
+	var firstErr error
+	total := 0
+	err := filepath.WalkDir(root, func(p string, d fs.DirEntry, err error) error {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err == nil {
+			if p != root && d.Type()&fs.ModeSymlink != 0 {
+				return nil
+			}
+			var info fs.FileInfo
+			if info, err = d.Info(); err == nil {
+				err = apply(p, info)
+			}
+		}
+		total++
+		if err != nil {
+			failed = append(failed, p)
+			if firstErr == nil {
+				firstErr = err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return err
+	}
+
+	if len(failed) > 0 {
+		return fmt.Errorf("%d of %d entries failed, first %s: %v", len(failed), total, failed[0], firstErr)
+	}
+	return nil
+}
+
+// showProperties shows the attributes of an entry with editors for its
+// mode, owner and group
+func (s *session) showProperties(target propertiesTarget) {
+	info := target.info
+	mode := info.Mode()
+
+	typeName := fileTypeName(mode)
+	if target.linkTarget != "" && mode&os.ModeSymlink == 0 {
+		typeName += " (through symbolic link)"
+	}
+	details := widget.NewForm(
+		widget.NewFormItem("Path", widget.NewLabel(target.path)),
+		widget.NewFormItem("Type", widget.NewLabel(typeName)),
+		widget.NewFormItem("Size", widget.NewLabel(fmt.Sprintf("%s (%d bytes)", humanSize(info.Size()), info.Size()))),
+		widget.NewFormItem("Modified", widget.NewLabel(info.ModTime().Format("2006-01-02 15:04:05"))),
+	)
+	if !target.accessed.IsZero() {
+		details.Append("Accessed", widget.NewLabel(target.accessed.Format("2006-01-02 15:04:05")))
+	}
+	if target.linkTarget != "" {
+		details.Append("Link target", widget.NewLabel(target.linkTarget))
+	}
+
+	editor := newModeEditor(mode)
+	if target.chmod == nil {
+		editor.disable()
+	}
+
+	uid, gid := target.uid, target.gid
+	uidEntry := widget.NewEntry()
+	gidEntry := widget.NewEntry()
+	if uid >= 0 {
+		uidEntry.SetText(strconv.Itoa(uid))
+		gidEntry.SetText(strconv.Itoa(gid))
+	}
+	if target.chown == nil {
+		uidEntry.Disable()
+		gidEntry.Disable()
+	}
+
+	// Execute bits only go to folders and to files that are already
+	// executable, see treeMode
+	recursiveCheck := widget.NewCheck("Apply to all contained files and folders", nil)
+	if !info.IsDir() || target.chmod == nil && target.chown == nil {
+		recursiveCheck.Disable()
+	}
+
+	content := container.NewVBox(
+		details,
+		widget.NewSeparator(),
+		widget.NewLabelWithStyle("Permissions", fyne.TextAlignLeading, fyne.TextStyle{Bold: true}),
+		editor.content(),
+		widget.NewSeparator(),
+		widget.NewForm(
+			widget.NewFormItem("Owner (UID)", uidEntry),
+			widget.NewFormItem("Group (GID)", gidEntry),
+		),
+		recursiveCheck,
+	)
+
+	title := "Properties: " + info.Name()
+	if target.chmod == nil && target.chown == nil {
+		d := dialog.NewCustom(title, "Close", content, s.window)
+		d.Resize(fyne.NewSize(480, 0))
+		d.Show()
+		return
+	}
+
+	d := dialog.NewCustomConfirm(title, "Apply", "Close", content, func(apply bool) {
+		if !apply {
+			return
+		}
+
+		newMode, err := editor.mode()
+		if err != nil {
+			s.showError(err.Error())
+			return
+		}
+		newUID, newGID := uid, gid
+		if target.chown != nil && (uidEntry.Text != "" || gidEntry.Text != "") {
+			if newUID, err = strconv.Atoi(strings.TrimSpace(uidEntry.Text)); err != nil {
+				s.showError("Owner must be a numeric UID")
+				return
+			}
+			if newGID, err = strconv.Atoi(strings.TrimSpace(gidEntry.Text)); err != nil {
+				s.showError("Group must be a numeric GID")
+				return
+			}
+		}
+
+		recursive := recursiveCheck.Checked
+		changeMode := target.chmod != nil &&
+			(newMode != mode&(os.ModePerm|os.ModeSetuid|os.ModeSetgid|os.ModeSticky) || recursive)
+		// Like the mode, an unchanged owner is still given to everything
+		// below when the change is recursive
+		changeOwner := target.chown != nil && newUID >= 0 &&
+			(newUID != uid || newGID != gid || recursive)
+
+		s.showProgress(fmt.Sprintf("Applying properties to %s...", target.path))
+		ctx, done := s.startOperation()
+		go func() {
+			defer done()
+			defer s.hideProgress()
+			if changeMode {
+				if err := target.chmod(ctx, newMode, recursive); ctx.Err() != nil {
+					s.logMessage(fmt.Sprintf("Changing permissions of %s cancelled", target.path))
+					return
+				} else if err != nil {
+					s.showError(fmt.Sprintf("Change permissions failed: %v", err))
+					return
+				}
+				s.logMessage(fmt.Sprintf("Changed permissions of %s to %s", target.path, modeToOctal(newMode)))
+			}
+			if changeOwner {
+				if err := target.chown(ctx, newUID, newGID, recursive); ctx.Err() != nil {
+					s.logMessage(fmt.Sprintf("Changing owner of %s cancelled", target.path))
+					return
+				} else if err != nil {
+					s.showError(fmt.Sprintf("Change owner failed: %v", err))
+					return
+				}
+				s.logMessage(fmt.Sprintf("Changed owner of %s to %d:%d", target.path, newUID, newGID))
+			}
+			s.do(target.refresh)
+		}()
+	}, s.window)
+	d.Resize(fyne.NewSize(480, 0))
+	d.Show()
+}
+
+// fileTypeName describes the type of a file mode
+func fileTypeName(mode os.FileMode) string {
+	switch {
+	case mode&os.ModeSymlink != 0:
+		return "Symbolic link"
+	case mode.IsDir():
+		return "Directory"
+	case mode&os.ModeNamedPipe != 0:
+		return "Named pipe"
+	case mode&os.ModeSocket != 0:
+		return "Socket"
+	case mode&os.ModeDevice != 0:
+		return "Device"
+	default:
+		return "File"
+	}
+}
+
+// modeEditor is an rwx checkbox grid kept in sync with an octal entry
+type modeEditor struct {
+	checks  [3][3]*widget.Check
+	special []*widget.Check
+	octal   *widget.Entry
+	syncing bool
+}
+
+func newModeEditor(mode os.FileMode) *modeEditor {
+	e := &modeEditor{octal: widget.NewEntry()}
+	for i := range permissionBits {
+		for j := range permissionBits[i] {
+			e.checks[i][j] = widget.NewCheck("", func(bool) { e.updateOctal() })
+		}
+	}
+	for _, bit := range specialBits {
+		e.special = append(e.special, widget.NewCheck(bit.label, func(bool) { e.updateOctal() }))
+	}
+	e.octal.Validator = func(text string) error {
+		_, err := octalToMode(text)
+		return err
+	}
+	e.octal.OnChanged = func(text string) {
+		if m, err := octalToMode(text); err == nil {
+			e.updateChecks(m)
+		}
+	}
+	e.updateChecks(mode)
+	e.updateOctal()
+	return e
+}
+
+// mode returns the mode currently described by the editor
+func (e *modeEditor) mode() (os.FileMode, error) {
+	return octalToMode(e.octal.Text)
+}
+
+func (e *modeEditor) updateOctal() {
+	if e.syncing {
+		return
+	}
+	var mode os.FileMode
+	for i := range permissionBits {
+		for j, bit := range permissionBits[i] {
+			if e.checks[i][j].Checked {
+				mode |= bit
+			}
+		}
+	}
+	for i, bit := range specialBits {
+		if e.special[i].Checked {
+			mode |= bit.mode
+		}
+	}
+	e.syncing = true
+	e.octal.SetText(modeToOctal(mode))
+	e.syncing = false
+}
+
+func (e *modeEditor) updateChecks(mode os.FileMode) {
+	if e.syncing {
+		return
+	}
+	e.syncing = true
+	for i := range permissionBits {
+		for j, bit := range permissionBits[i] {
+			e.checks[i][j].SetChecked(mode&bit != 0)
+		}
+	}
+	for i, bit := range specialBits {
+		e.special[i].SetChecked(mode&bit.mode != 0)
+	}
+	e.syncing = false
+}
+
+// disable makes the editor read-only
+func (e *modeEditor) disable() {
+	for i := range e.checks {
+		for _, check := range e.checks[i] {
+			check.Disable()
+		}
+	}
+	for _, check := range e.special {
+		check.Disable()
+	}
+	e.octal.Disable()
+}
+
+// content lays out the checkbox grid, the special bits and the octal entry
+func (e *modeEditor) content() fyne.CanvasObject {
+	grid := container.NewGridWithColumns(4,
+		widget.NewLabel(""), widget.NewLabel("Read"), widget.NewLabel("Write"), widget.NewLabel("Execute"))
+	for i, who := range []string{"Owner", "Group", "Others"} {
+		grid.Add(widget.NewLabel(who))
+		for j := range permissionBits[i] {
+			grid.Add(e.checks[i][j])
+		}
+	}
+
+	special := container.NewHBox()
+	for _, check := range e.special {
+		special.Add(check)
+	}
+
+	return container.NewVBox(
+		grid,
+		special,
+		container.NewBorder(nil, nil, widget.NewLabel("Octal:"), nil, e.octal),
+	)
+}
@@ -0,0 +1,143 @@
+package main
+
+import (
+	"context"
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"fyne.io/fyne/v2/test"
+)
+
+func TestOctalMode(t *testing.T) {
+	tests := []struct {
+		text string
+		mode os.FileMode
+	}{
+		{"755", 0755},
+		{"0644", 0644},
+		{"4755", os.ModeSetuid | 0755},
+		{"1777", os.ModeSticky | 0777},
+		{"2750", os.ModeSetgid | 0750},
+	}
+
+	for _, tt := range tests {
+		mode, err := octalToMode(tt.text)
+		if err != nil {
+			t.Errorf("octalToMode(%q) failed: %v", tt.text, err)
+			continue
+		}
+		if mode != tt.mode {
+			t.Errorf("octalToMode(%q) = %v, want %v", tt.text, mode, tt.mode)
+		}
+		if back, _ := octalToMode(modeToOctal(mode)); back != mode {
+			t.Errorf("modeToOctal(%v) does not round trip", mode)
+		}
+	}
+
+	for _, text := range []string{"", "9", "17777", "rwx"} {
+		if _, err := octalToMode(text); err == nil {
+			t.Errorf("octalToMode(%q) should fail", text)
+		}
+	}
+}
+
+func TestModeEditor(t *testing.T) {
+	test.NewApp()
+
+	editor := newModeEditor(0640)
+	if editor.octal.Text != "0640" {
+		t.Fatalf("Expected octal 0640, got %q", editor.octal.Text)
+	}
+	if !editor.checks[0][0].Checked || !editor.checks[1][0].Checked || editor.checks[2][0].Checked {
+		t.Error("Checkboxes do not match mode 0640")
+	}
+
+	editor.checks[0][2].SetChecked(true)
+	if editor.octal.Text != "0740" {
+		t.Errorf("Expected octal 0740 after checking owner execute, got %q", editor.octal.Text)
+	}
+
+	editor.octal.SetText("4755")
+	if !editor.checks[2][2].Checked || !editor.special[0].Checked {
+		t.Error("Checkboxes not updated from octal entry")
+	}
+	if mode, _ := editor.mode(); mode != os.ModeSetuid|0755 {
+		t.Errorf("Expected setuid 0755, got %v", mode)
+	}
+}
+
+func TestSFTPGUIClient_ChmodRecursive(t *testing.T) {
+	client, root := newTestClient(t)
+	writeTestFiles(t, root, map[string]string{
+		"dir/a.txt":     "a",
+		"dir/sub/b.txt": "b",
+		"dir/run.sh":    "#!/bin/sh",
+	})
+	if err := os.Chmod(filepath.Join(root, "dir", "run.sh"), 0700); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := client.Chmod(root+"/dir/a.txt", 0600, false); err != nil {
+		t.Fatalf("Chmod failed: %v", err)
+	}
+	info, _ := os.Stat(filepath.Join(root, "dir", "a.txt"))
+	if info.Mode().Perm() != 0600 {
+		t.Errorf("Expected 0600, got %v", info.Mode().Perm())
+	}
+
+	if err := client.Chmod(root+"/dir", 0750, true); err != nil {
+		t.Fatalf("Recursive chmod failed: %v", err)
+	}
+	// Only folders and files that were executable get execute bits
+	want := map[string]os.FileMode{
+		"dir": 0750, "dir/a.txt": 0640, "dir/sub": 0750, "dir/sub/b.txt": 0640, "dir/run.sh": 0750,
+	}
+	for p, perm := range want {
+		info, err := os.Stat(filepath.Join(root, p))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if info.Mode().Perm() != perm {
+			t.Errorf("Expected %s to be %v, got %v", p, perm, info.Mode().Perm())
+		}
+	}
+}
+
+func TestApplyLocalRecursive(t *testing.T) {
+	root := t.TempDir()
+	writeTestFiles(t, root, map[string]string{
+		"dir/a.txt":     "a",
+		"dir/sub/b.txt": "b",
+	})
+
+	var visited []string
+	err := applyLocalRecursive(context.Background(), filepath.Join(root, "dir"), true, func(p string, info os.FileInfo) error {
+		visited = append(visited, p)
+		return os.Chmod(p, treeMode(0750, info.Mode()))
+	})
+	if err != nil {
+		t.Fatalf("applyLocalRecursive failed: %v", err)
+	}
+	if len(visited) != 4 {
+		t.Errorf("Expected 4 entries, got %v", visited)
+	}
+	if info, _ := os.Stat(filepath.Join(root, "dir", "sub", "b.txt")); info.Mode().Perm() != 0640 {
+		t.Errorf("Expected 0640, got %v", info.Mode().Perm())
+	}
+	if info, _ := os.Stat(filepath.Join(root, "dir", "sub")); info.Mode().Perm() != 0750 {
+		t.Errorf("Expected 0750, got %v", info.Mode().Perm())
+	}
+
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	visited = nil
+	err = applyLocalRecursive(ctx, filepath.Join(root, "dir"), true, func(p string, _ os.FileInfo) error {
+		visited = append(visited, p)
+		return nil
+	})
+	if !errors.Is(err, context.Canceled) || len(visited) != 0 {
+		t.Errorf("Expected context.Canceled before any entry, got %v after %v", err, visited)
+	}
+}
@@ -0,0 +1,378 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"strings"
+
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+	"github.com/pkg/sftp"
+)
+
+// Ways of sending files between two sessions
+const (
+	relayStream = "Stream through this computer"
+	relayDirect = "Run scp on the source server"
+)
+
+// progressWriter reports the bytes written through it
+type progressWriter struct {
+	w        io.Writer
+	progress func(written int64)
+}
+
+func (p *progressWriter) Write(data []byte) (int, error) {
+	n, err := p.w.Write(data)
+	if n > 0 && p.progress != nil {
+		p.progress(int64(n))
+	}
+	return n, err
+}
+
+// CopyTo copies srcPath on the server of c to dstPath on the server of
+// dst, recursing into folders. The data streams through this computer
+// without touching the local disk. Symlinks are recreated unless
+// followLinks is set, and progress receives the bytes copied since its
+// last call.
+func (c *SFTPGUIClient) CopyTo(dst *SFTPGUIClient, srcPath, dstPath string, followLinks bool, progress func(written int64)) error {
+	return c.CopyToContext(context.Background(), dst, srcPath, dstPath, followLinks, progress)
+}
+
+// CopyToContext is CopyTo stopping with the error of ctx as soon as ctx
+// is done. The file being copied is closed on both servers and its
+// partial copy removed.
+func (c *SFTPGUIClient) CopyToContext(ctx context.Context, dst *SFTPGUIClient, srcPath, dstPath string, followLinks bool, progress func(written int64)) error {
+	if !c.IsConnected() || !dst.IsConnected() {
+		return fmt.Errorf("not connected")
+	}
+
+	walker := c.sftpClient.Walk(srcPath)
+	for walker.Step() {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+		if err := walker.Err(); err != nil {
+			return err
+		}
+		p, info := walker.Path(), walker.Stat()
+		target := path.Join(dstPath, strings.TrimPrefix(p, srcPath))
+
+		if info.Mode()&os.ModeSymlink != 0 {
+			resolved, err := c.sftpClient.Stat(p)
+			if !followLinks || err != nil || resolved.IsDir() {
+				// Linked folders are recreated as links to avoid loops
+				linkTarget, err := c.ReadLink(p)
+				if err != nil {
+					return err
+				}
+				if err := dst.Symlink(linkTarget, target); err != nil {
+					return err
+				}
+				continue
+			}
+			info = resolved
+		}
+
+		if info.IsDir() {
+			if err := dst.sftpClient.MkdirAll(target); err != nil {
+				return err
+			}
+			dst.sftpClient.Chmod(target, info.Mode().Perm())
+			continue
+		}
+		if err := c.copyFileTo(ctx, dst, p, target, info, progress); err != nil {
+			if ctx.Err() != nil {
+				return ctx.Err()
+			}
+			return fmt.Errorf("%s: %v", p, err)
+		}
+	}
+	return nil
+}
+
+// copyFileTo streams one regular file to dst, keeping its mode and
+// modification time. An existing file at dstPath is replaced; the caller
+// asks first.
+func (c *SFTPGUIClient) copyFileTo(ctx context.Context, dst *SFTPGUIClient, srcPath, dstPath string, info os.FileInfo, progress func(written int64)) error {
+	srcFile, err := openContext(ctx, func() (*sftp.File, error) {
+		return c.sftpClient.Open(srcPath)
+	}, nil)
+	if err != nil {
+		return err
+	}
+	// Files closed on cancel are not closed again, which would wait for
+	// the reply to the first close
+	stopSrc := closeOnCancel(ctx, srcFile)
+	defer func() {
+		if stopSrc() {
+			srcFile.Close()
+		}
+	}()
+
+	dstFile, err := openContext(ctx, func() (*sftp.File, error) {
+		return dst.sftpClient.Create(dstPath)
+	}, func() { dst.removePartial(dstPath) })
+	if err != nil {
+		return err
+	}
+	stopDst := closeOnCancel(ctx, dstFile)
+	_, err = io.Copy(&progressWriter{dstFile, progress}, srcFile)
+	if !stopDst() {
+		dst.removePartial(dstPath)
+		return ctx.Err()
+	}
+	if ctx.Err() != nil {
+		dstFile.Close()
+		dst.removePartial(dstPath)
+		return ctx.Err()
+	}
+	if err != nil {
+		dstFile.Close()
+		return err
+	}
+	if err := dstFile.Close(); err != nil {
+		return err
+	}
+
+	dst.sftpClient.Chmod(dstPath, info.Mode().Perm())
+	return dst.sftpClient.Chtimes(dstPath, info.ModTime(), info.ModTime())
+}
+
+// Run runs command on the server in a new SSH session and returns its
+// combined output
+func (c *SFTPGUIClient) Run(command string) ([]byte, error) {
+	return c.RunContext(context.Background(), command)
+}
+
+// RunContext is Run closing the SSH session as soon as ctx is done, which
+// stops waiting for the command
+func (c *SFTPGUIClient) RunContext(ctx context.Context, command string) ([]byte, error) {
+	c.mu.Lock()
+	sshClient, connected := c.sshClient, c.connected
+	c.mu.Unlock()
+	if !connected || sshClient == nil {
+		return nil, fmt.Errorf("not connected")
+	}
+
+	sshSession, err := sshClient.NewSession()
+	if err != nil {
+		return nil, err
+	}
+	defer sshSession.Close()
+	defer closeOnCancel(ctx, sshSession)()
+	output, err := sshSession.CombinedOutput(command)
+	return output, cancelled(ctx, err)
+}
+
+// scpCommand builds the scp command line copying srcPaths into dstDir on
+// user@host. Batch mode makes scp fail instead of asking for a password,
+// so the source server needs key access to the destination.
+func scpCommand(srcPaths []string, user, host string, port int, dstDir string) string {
+	args := []string{"scp", "-r", "-p", "-B", "-P", fmt.Sprint(port)}
+	for _, p := range srcPaths {
+		args = append(args, shellQuote(p))
+	}
+	args = append(args, shellQuote(fmt.Sprintf("%s@%s:%s", user, host, dstDir)))
+	return strings.Join(args, " ")
+}
+
+// shellQuote quotes s for a POSIX shell
+func shellQuote(s string) string {
+	return "'" + strings.ReplaceAll(s, "'", `'\''`) + "'"
+}
+
+// onSendToSession copies the selected remote entries into a folder of
+// another session's server
+func (s *session) onSendToSession() {
+	if !s.client.IsConnected() {
+		return
+	}
+	names := s.remotePane.selectedNames()
+	if len(names) == 0 {
+		s.showError("Please select remote files to send")
+		return
+	}
+
+	var targets []*session
+	var titles []string
+	for _, other := range s.sessions {
+		if other != s && other.client.IsConnected() {
+			targets = append(targets, other)
+			titles = append(titles, other.title)
+		}
+	}
+	if len(targets) == 0 {
+		s.showError("Connect a second session to send files to")
+		return
+	}
+
+	dirEntry := widget.NewEntry()
+	targetSelect := widget.NewSelect(titles, nil)
+	targetSelect.OnChanged = func(string) {
+		dirEntry.SetText(targets[targetSelect.SelectedIndex()].currentRemote)
+	}
+	targetSelect.SetSelectedIndex(0)
+
+	methodRadio := widget.NewRadioGroup([]string{relayStream, relayDirect}, nil)
+	methodRadio.Required = true
+	methodRadio.SetSelected(relayStream)
+
+	srcDir := s.currentRemote
+	dialog.ShowForm(fmt.Sprintf("Send %d item(s)", len(names)), "Send", "Cancel",
+		[]*widget.FormItem{
+			widget.NewFormItem("Session", targetSelect),
+			widget.NewFormItem("Folder", dirEntry),
+			widget.NewFormItem("Method", methodRadio),
+		},
+		func(confirmed bool) {
+			if !confirmed || dirEntry.Text == "" {
+				return
+			}
+			target := targets[targetSelect.SelectedIndex()]
+			s.sendToSession(target, srcDir, names, dirEntry.Text, methodRadio.Selected == relayDirect)
+		}, s.window)
+}
+
+// sendToSession asks before replacing entries that already exist in
+// dstDir on the target server, then sizes the entries to send. The
+// target server is asked in the background.
+func (s *session) sendToSession(target *session, srcDir string, names []string, dstDir string, direct bool) {
+	s.showProgress(fmt.Sprintf("Checking %d item(s) to send...", len(names)))
+	ctx, done := s.startOperation()
+	go func() {
+		defer done()
+		var existing []string
+		for _, name := range names {
+			if ctx.Err() != nil {
+				break
+			}
+			if ok, _ := target.client.Exists(path.Join(dstDir, name)); ok {
+				existing = append(existing, name)
+			}
+		}
+		s.hideProgress()
+		if ctx.Err() != nil {
+			s.logMessage("Send cancelled")
+			return
+		}
+
+		send := func(skip []string) {
+			skipped := make(map[string]bool)
+			for _, name := range skip {
+				skipped[name] = true
+			}
+			var paths []string
+			for _, name := range names {
+				if !skipped[name] {
+					paths = append(paths, path.Join(srcDir, name))
+				}
+			}
+			if len(paths) > 0 {
+				s.sizeAndRelay(target, paths, dstDir, direct)
+			}
+		}
+		s.do(func() {
+			if len(existing) == 0 {
+				send(nil)
+				return
+			}
+			s.askConflict(existing, len(names), func(action conflictAction) {
+				switch action {
+				case conflictSkip:
+					send(existing)
+				case conflictOverwrite:
+					send(nil)
+				}
+			})
+		})
+	}()
+}
+
+// sizeAndRelay sizes the entries, checks the space left on the target
+// server and then starts the transfer
+func (s *session) sizeAndRelay(target *session, paths []string, dstDir string, direct bool) {
+	s.showProgress(fmt.Sprintf("Calculating size of %d item(s)...", len(paths)))
+	ctx, done := s.startOperation()
+	go func() {
+		defer done()
+		summary, err := s.client.ScanTreeContext(ctx, paths)
+		s.hideProgress()
+		if ctx.Err() != nil {
+			s.logMessage("Send cancelled")
+			return
+		}
+		if err != nil {
+			s.showError(fmt.Sprintf("Send failed: %v", err))
+			return
+		}
+		target.checkDiskSpace(dstDir, summary.Bytes, func() {
+			s.relay(target, paths, dstDir, summary.Bytes, direct)
+		})
+	}()
+}
+
+// relay copies paths into dstDir on the target's server. The progress bar
+// follows the bytes copied when streaming; scp reports nothing until it
+// has finished.
+func (s *session) relay(target *session, paths []string, dstDir string, total int64, direct bool) {
+	s.setProgress(0)
+	s.showProgress(fmt.Sprintf("Sending %d item(s) to %s:%s...", len(paths), target.title, dstDir))
+
+	followLinks := s.settings.FollowLinks
+	ctx, done := s.startOperation()
+	go func() {
+		defer done()
+		sent, failed := 0, 0
+		if direct {
+			command := scpCommand(paths, target.user, target.host, target.port, dstDir)
+			output, err := s.client.RunContext(ctx, command)
+			if err != nil && ctx.Err() == nil {
+				failed = len(paths)
+				s.logMessage(fmt.Sprintf("scp on %s failed: %v %s", s.title, err, strings.TrimSpace(string(output))))
+			} else if err == nil {
+				sent = len(paths)
+				for _, p := range paths {
+					s.logMessage(fmt.Sprintf("Sent: %s → %s:%s", p, target.title, dstDir))
+				}
+			}
+		} else {
+			var copied int64
+			for _, p := range paths {
+				err := s.client.CopyToContext(ctx, target.client, p, path.Join(dstDir, path.Base(p)), followLinks, func(written int64) {
+					copied += written
+					if total > 0 {
+						s.setProgress(float64(copied) / float64(total))
+					}
+				})
+				if ctx.Err() != nil {
+					break
+				}
+				if err != nil {
+					failed++
+					s.logMessage(fmt.Sprintf("Send failed for %s: %v", p, err))
+				} else {
+					sent++
+					s.logMessage(fmt.Sprintf("Sent: %s → %s:%s", p, target.title, dstDir))
+				}
+			}
+		}
+		s.setProgress(1)
+		s.hideProgress()
+
+		if ctx.Err() != nil {
+			s.logMessage(fmt.Sprintf("Send cancelled after %d of %d item(s)", sent, len(paths)))
+		} else if failed > 0 {
+			s.showError(fmt.Sprintf("Send failed for %d of %d item(s)", failed, len(paths)))
+		} else {
+			target.logMessage(fmt.Sprintf("Received %d item(s) from %s in %s", len(paths), s.title, dstDir))
+		}
+		for _, p := range paths {
+			target.remoteChanged(path.Join(dstDir, path.Base(p)))
+		}
+		target.do(target.updateRemoteFiles)
+	}()
+}
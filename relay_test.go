@@ -0,0 +1,61 @@
+package main
+
+import (
+	"os"
+	"path"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+func TestSFTPGUIClient_CopyTo(t *testing.T) {
+	src, srcRoot := newTestClient(t)
+	dst, dstRoot := newTestClient(t)
+	writeTestFiles(t, srcRoot, map[string]string{
+		"release/app.bin":        "binary",
+		"release/conf/app.yaml":  "port: 80",
+		"release/conf/empty.txt": "",
+	})
+	if err := os.Symlink("app.bin", filepath.Join(srcRoot, "release", "current")); err != nil {
+		t.Fatal(err)
+	}
+	mtime := time.Now().Add(-time.Hour).Truncate(time.Second)
+	os.Chtimes(filepath.Join(srcRoot, "release", "app.bin"), mtime, mtime)
+
+	var copied int64
+	err := src.CopyTo(dst, path.Join(srcRoot, "release"), path.Join(dstRoot, "staged"), false, func(written int64) {
+		copied += written
+	})
+	if err != nil {
+		t.Fatalf("CopyTo failed: %v", err)
+	}
+
+	for name, want := range map[string]string{
+		"app.bin":        "binary",
+		"conf/app.yaml":  "port: 80",
+		"conf/empty.txt": "",
+	} {
+		data, err := os.ReadFile(filepath.Join(dstRoot, "staged", filepath.FromSlash(name)))
+		if err != nil || string(data) != want {
+			t.Errorf("%s = %q, %v; want %q", name, data, err, want)
+		}
+	}
+	if copied != int64(len("binary")+len("port: 80")) {
+		t.Errorf("Progress reported %d bytes", copied)
+	}
+	if info, err := os.Stat(filepath.Join(dstRoot, "staged", "app.bin")); err != nil || !info.ModTime().Equal(mtime) {
+		t.Errorf("Modification time not kept: %v, %v", info, err)
+	}
+	// The test server stores link targets as absolute paths
+	if target, err := os.Readlink(filepath.Join(dstRoot, "staged", "current")); err != nil || filepath.Base(target) != "app.bin" {
+		t.Errorf("Symlink = %q, %v; want a link to app.bin", target, err)
+	}
+}
+
+func TestScpCommand(t *testing.T) {
+	got := scpCommand([]string{"/srv/a b", "/srv/it's"}, "deploy", "prod", 2222, "/var/www")
+	want := `scp -r -p -B -P 2222 '/srv/a b' '/srv/it'\''s' 'deploy@prod:/var/www'`
+	if got != want {
+		t.Errorf("scpCommand = %s\nwant %s", got, want)
+	}
+}
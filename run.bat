@@ -67,7 +67,7 @@ if %errorlevel% neq 0 (
 
 echo 🔨 Building SFTP Client GUI...
 set CGO_ENABLED=1
-go build -o sftp-client-gui.exe main.go app_icon.go
+go build -o sftp-client-gui.exe .
 if %errorlevel% neq 0 (
     echo ❌ Error: GUI Build failed - trying CLI version...
     goto build_cli
@@ -44,7 +44,7 @@ if ! go mod tidy; then
 fi
 
 echo "🔨 Building SFTP Client GUI..."
-if ! go build -o sftp-client-gui main.go app_icon.go; then
+if ! go build -o sftp-client-gui .; then
     echo "❌ Error: Build failed"
     exit 1
 fi
@@ -0,0 +1,283 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// searchCriteria describes which remote entries a search should report.
+// Zero values disable the corresponding check.
+type searchCriteria struct {
+	match          func(name string) bool
+	minSize        int64
+	maxSize        int64
+	modifiedAfter  time.Time
+	modifiedBefore time.Time
+}
+
+// matches reports whether info satisfies every criterion
+func (c searchCriteria) matches(info os.FileInfo) bool {
+	if c.match != nil && !c.match(info.Name()) {
+		return false
+	}
+	if c.minSize > 0 && info.Size() < c.minSize {
+		return false
+	}
+	if c.maxSize > 0 && info.Size() > c.maxSize {
+		return false
+	}
+	if !c.modifiedAfter.IsZero() && info.ModTime().Before(c.modifiedAfter) {
+		return false
+	}
+	if !c.modifiedBefore.IsZero() && !info.ModTime().Before(c.modifiedBefore) {
+		return false
+	}
+	return true
+}
+
+// parseSize parses a size such as "512", "10K", "1.5M" or "2G" into bytes.
+// An empty string yields 0.
+func parseSize(text string) (int64, error) {
+	text = strings.ToUpper(strings.TrimSpace(text))
+	if text == "" {
+		return 0, nil
+	}
+	text = strings.TrimSuffix(strings.TrimSuffix(text, "B"), "I")
+
+	multiplier := int64(1)
+	if n := len(text); n > 0 {
+		if i := strings.IndexByte("KMGT", text[n-1]); i >= 0 {
+			multiplier = int64(1) << (10 * (i + 1))
+			text = text[:n-1]
+		}
+	}
+
+	value, err := strconv.ParseFloat(strings.TrimSpace(text), 64)
+	if err != nil || value < 0 {
+		return 0, fmt.Errorf("invalid size: %q", text)
+	}
+	return int64(value * float64(multiplier)), nil
+}
+
+// parseDate parses a YYYY-MM-DD date in local time. An empty string
+// yields the zero time.
+func parseDate(text string) (time.Time, error) {
+	text = strings.TrimSpace(text)
+	if text == "" {
+		return time.Time{}, nil
+	}
+	date, err := time.ParseInLocation("2006-01-02", text, time.Local)
+	if err != nil {
+		return time.Time{}, fmt.Errorf("invalid date %q, expected YYYY-MM-DD", text)
+	}
+	return date, nil
+}
+
+// Find walks the remote tree below root and calls found for every entry
+// matching criteria. Unreadable directories are skipped. The walk stops
+// with the error of ctx as soon as ctx is done.
+func (c *SFTPGUIClient) Find(ctx context.Context, root string, criteria searchCriteria, found func(p string, info os.FileInfo)) error {
+	if !c.IsConnected() {
+		return fmt.Errorf("not connected")
+	}
+
+	walker := c.sftpClient.Walk(root)
+	for walker.Step() {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		if walker.Err() != nil {
+			continue
+		}
+		if walker.Path() == root {
+			continue
+		}
+		if criteria.matches(walker.Stat()) {
+			found(walker.Path(), walker.Stat())
+		}
+	}
+	return nil
+}
+
+// showFindDialog shows the recursive remote search dialog
+func (s *session) showFindDialog() {
+	if !s.client.IsConnected() {
+		s.showError("Please connect to a server before searching")
+		return
+	}
+
+	rootEntry := widget.NewEntry()
+	rootEntry.SetText(s.currentRemote)
+	nameEntry := widget.NewEntry()
+	nameEntry.SetPlaceHolder("Name pattern")
+	modeSelect := widget.NewSelect(matchModes, nil)
+	modeSelect.SetSelected(matchGlob)
+	minSizeEntry := widget.NewEntry()
+	minSizeEntry.SetPlaceHolder("e.g. 10K")
+	maxSizeEntry := widget.NewEntry()
+	maxSizeEntry.SetPlaceHolder("e.g. 1.5G")
+	afterEntry := widget.NewEntry()
+	afterEntry.SetPlaceHolder("YYYY-MM-DD")
+	beforeEntry := widget.NewEntry()
+	beforeEntry.SetPlaceHolder("YYYY-MM-DD")
+	statusLabel := widget.NewLabel("")
+
+	var mu sync.Mutex
+	var results []string
+	resultList := widget.NewList(
+		func() int {
+			mu.Lock()
+			defer mu.Unlock()
+			return len(results)
+		},
+		func() fyne.CanvasObject {
+			return widget.NewLabel("template")
+		},
+		func(id widget.ListItemID, obj fyne.CanvasObject) {
+			mu.Lock()
+			defer mu.Unlock()
+			obj.(*widget.Label).SetText(results[id])
+		},
+	)
+
+	// cancel stops the running search. The search is also a session
+	// operation, so the Cancel button of the main window stops it too.
+	var cancel context.CancelFunc
+	var searchBtn, stopBtn *widget.Button
+	stopSearch := func() {
+		if cancel != nil {
+			cancel()
+			cancel = nil
+		}
+	}
+
+	searchBtn = widget.NewButtonWithIcon("Search", theme.SearchIcon(), func() {
+		criteria, err := buildSearchCriteria(modeSelect.Selected, nameEntry.Text,
+			minSizeEntry.Text, maxSizeEntry.Text, afterEntry.Text, beforeEntry.Text)
+		if err != nil {
+			statusLabel.SetText(err.Error())
+			return
+		}
+
+		stopSearch()
+		mu.Lock()
+		results = nil
+		mu.Unlock()
+		resultList.UnselectAll()
+		resultList.Refresh()
+
+		opCtx, done := s.startOperation()
+		ctx, searchCancel := context.WithCancel(opCtx)
+		cancel = searchCancel
+		root := rootEntry.Text
+		searchBtn.Disable()
+		stopBtn.Enable()
+		statusLabel.SetText("Searching...")
+
+		go func() {
+			defer done()
+			defer searchCancel()
+			count := 0
+			lastRefresh := time.Now()
+			err := s.client.Find(ctx, root, criteria, func(p string, info os.FileInfo) {
+				mu.Lock()
+				results = append(results, p)
+				mu.Unlock()
+				count++
+				// Stream results without redrawing for every single match
+				if time.Since(lastRefresh) > 200*time.Millisecond {
+					status := fmt.Sprintf("Searching... %d found", count)
+					s.do(func() {
+						resultList.Refresh()
+						statusLabel.SetText(status)
+					})
+					lastRefresh = time.Now()
+				}
+			})
+
+			status := fmt.Sprintf("Done, %d found", count)
+			switch {
+			case ctx.Err() != nil:
+				status = fmt.Sprintf("Stopped, %d found", count)
+			case err != nil:
+				status = fmt.Sprintf("Search failed: %v", err)
+			}
+			s.do(func() {
+				resultList.Refresh()
+				statusLabel.SetText(status)
+				searchBtn.Enable()
+				stopBtn.Disable()
+			})
+		}()
+	})
+
+	stopBtn = widget.NewButtonWithIcon("Stop", theme.MediaStopIcon(), stopSearch)
+	stopBtn.Disable()
+
+	form := widget.NewForm(
+		widget.NewFormItem("Search in", rootEntry),
+		widget.NewFormItem("Name", container.NewBorder(nil, nil, nil, modeSelect, nameEntry)),
+		widget.NewFormItem("Size", container.NewGridWithColumns(2, minSizeEntry, maxSizeEntry)),
+		widget.NewFormItem("Modified", container.NewGridWithColumns(2, afterEntry, beforeEntry)),
+	)
+
+	content := container.NewBorder(
+		container.NewVBox(form, container.NewHBox(searchBtn, stopBtn, statusLabel)),
+		nil, nil, nil,
+		resultList,
+	)
+
+	d := dialog.NewCustom("Find Remote Files", "Close", content, s.window)
+	d.SetOnClosed(stopSearch)
+
+	resultList.OnSelected = func(id widget.ListItemID) {
+		mu.Lock()
+		result := results[id]
+		mu.Unlock()
+
+		// Jump to the result in the remote pane
+		stopSearch()
+		d.Hide()
+		s.navigateRemote(path.Dir(result), true)
+		s.remotePane.selectWhenLoaded(path.Base(result))
+	}
+
+	d.Resize(fyne.NewSize(700, 550))
+	d.Show()
+}
+
+// buildSearchCriteria validates the find dialog inputs
+func buildSearchCriteria(mode, pattern, minSize, maxSize, after, before string) (searchCriteria, error) {
+	var criteria searchCriteria
+	var err error
+
+	if criteria.match, err = newNameMatcher(mode, pattern); err != nil {
+		return criteria, err
+	}
+	if criteria.minSize, err = parseSize(minSize); err != nil {
+		return criteria, err
+	}
+	if criteria.maxSize, err = parseSize(maxSize); err != nil {
+		return criteria, err
+	}
+	if criteria.modifiedAfter, err = parseDate(after); err != nil {
+		return criteria, err
+	}
+	if criteria.modifiedBefore, err = parseDate(before); err != nil {
+		return criteria, err
+	}
+	return criteria, nil
+}
@@ -0,0 +1,60 @@
+package main
+
+import (
+	"context"
+	"os"
+	"testing"
+	"time"
+)
+
+func TestParseSize(t *testing.T) {
+	tests := map[string]int64{
+		"":      0,
+		"512":   512,
+		"10K":   10 * 1024,
+		"1.5M":  1536 * 1024,
+		"2GB":   2 << 30,
+		"1 KiB": 1024,
+	}
+	for text, want := range tests {
+		got, err := parseSize(text)
+		if err != nil || got != want {
+			t.Errorf("parseSize(%q) = %d, %v; want %d", text, got, err, want)
+		}
+	}
+
+	if _, err := parseSize("lots"); err == nil {
+		t.Error("parseSize should reject non-numeric input")
+	}
+}
+
+func TestSFTPGUIClient_Find(t *testing.T) {
+	client, root := newTestClient(t)
+	writeTestFiles(t, root, map[string]string{
+		"a.log":          "12345",
+		"sub/b.log":      "1234567890",
+		"sub/c.txt":      "x",
+		"sub/deep/d.log": "",
+	})
+
+	match, _ := newNameMatcher(matchGlob, "*.log")
+	criteria := searchCriteria{match: match, minSize: 1, modifiedAfter: time.Now().Add(-time.Hour)}
+
+	var found []string
+	err := client.Find(context.Background(), root, criteria, func(p string, _ os.FileInfo) {
+		found = append(found, p)
+	})
+	if err != nil {
+		t.Fatalf("Find failed: %v", err)
+	}
+
+	want := map[string]bool{root + "/a.log": true, root + "/sub/b.log": true}
+	if len(found) != len(want) {
+		t.Fatalf("Expected %d results, got %v", len(want), found)
+	}
+	for _, p := range found {
+		if !want[p] {
+			t.Errorf("Unexpected result %s", p)
+		}
+	}
+}
@@ -0,0 +1,128 @@
+package main
+
+import (
+	"sort"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/driver/desktop"
+)
+
+// fileSelection tracks a multi-item selection over a list of entries.
+// It follows the usual file manager semantics: a plain click selects a
+// single item, Ctrl toggles an item and Shift extends a range from the
+// last anchor.
+type fileSelection struct {
+	selected map[int]bool
+	anchor   int
+}
+
+// newFileSelection creates an empty selection
+func newFileSelection() *fileSelection {
+	return &fileSelection{
+		selected: make(map[int]bool),
+		anchor:   -1,
+	}
+}
+
+// Click applies a click on item id with the given key modifiers
+func (s *fileSelection) Click(id int, mod fyne.KeyModifier) {
+	switch {
+	case mod&fyne.KeyModifierShift != 0 && s.anchor >= 0:
+		s.ExtendTo(id, mod&(fyne.KeyModifierControl|fyne.KeyModifierSuper) != 0)
+	case mod&(fyne.KeyModifierControl|fyne.KeyModifierSuper) != 0:
+		s.Toggle(id)
+	default:
+		s.Set(id)
+	}
+}
+
+// Set replaces the selection with a single item
+func (s *fileSelection) Set(id int) {
+	s.selected = map[int]bool{id: true}
+	s.anchor = id
+}
+
+// Toggle adds or removes a single item and moves the anchor to it
+func (s *fileSelection) Toggle(id int) {
+	if s.selected[id] {
+		delete(s.selected, id)
+	} else {
+		s.selected[id] = true
+	}
+	s.anchor = id
+}
+
+// ExtendTo selects the range between the anchor and id. When keep is
+// false the previous selection is replaced by the range.
+func (s *fileSelection) ExtendTo(id int, keep bool) {
+	if s.anchor < 0 {
+		s.Set(id)
+		return
+	}
+	if !keep {
+		s.selected = make(map[int]bool)
+	}
+	from, to := s.anchor, id
+	if from > to {
+		from, to = to, from
+	}
+	for i := from; i <= to; i++ {
+		s.selected[i] = true
+	}
+}
+
+// SelectAll selects every item of a list with n entries
+func (s *fileSelection) SelectAll(n int) {
+	s.selected = make(map[int]bool, n)
+	for i := 0; i < n; i++ {
+		s.selected[i] = true
+	}
+	if n > 0 {
+		s.anchor = 0
+	}
+}
+
+// Invert flips the selection state of every item of a list with n entries
+func (s *fileSelection) Invert(n int) {
+	inverted := make(map[int]bool, n)
+	for i := 0; i < n; i++ {
+		if !s.selected[i] {
+			inverted[i] = true
+		}
+	}
+	s.selected = inverted
+}
+
+// Clear removes all items from the selection
+func (s *fileSelection) Clear() {
+	s.selected = make(map[int]bool)
+	s.anchor = -1
+}
+
+// IsSelected reports whether item id is selected
+func (s *fileSelection) IsSelected(id int) bool {
+	return s.selected[id]
+}
+
+// Count returns the number of selected items
+func (s *fileSelection) Count() int {
+	return len(s.selected)
+}
+
+// Indices returns the selected item ids in ascending order
+func (s *fileSelection) Indices() []int {
+	ids := make([]int, 0, len(s.selected))
+	for id := range s.selected {
+		ids = append(ids, id)
+	}
+	sort.Ints(ids)
+	return ids
+}
+
+// currentKeyModifiers returns the modifiers held down on desktop drivers
+func currentKeyModifiers() fyne.KeyModifier {
+	if drv, ok := fyne.CurrentApp().Driver().(desktop.Driver); ok {
+		return drv.CurrentKeyModifiers()
+	}
+	return 0
+}
@@ -0,0 +1,53 @@
+package main
+
+import (
+	"reflect"
+	"testing"
+
+	"fyne.io/fyne/v2"
+)
+
+func TestFileSelection_Click(t *testing.T) {
+	sel := newFileSelection()
+
+	sel.Click(2, 0)
+	sel.Click(4, fyne.KeyModifierControl)
+	if got := sel.Indices(); !reflect.DeepEqual(got, []int{2, 4}) {
+		t.Errorf("Ctrl-click should add to selection, got %v", got)
+	}
+
+	sel.Click(2, fyne.KeyModifierControl)
+	if got := sel.Indices(); !reflect.DeepEqual(got, []int{4}) {
+		t.Errorf("Ctrl-click on selected item should remove it, got %v", got)
+	}
+
+	sel.Click(1, fyne.KeyModifierShift)
+	if got := sel.Indices(); !reflect.DeepEqual(got, []int{1, 2}) {
+		t.Errorf("Shift-click should select range from anchor, got %v", got)
+	}
+
+	sel.Click(5, 0)
+	if got := sel.Indices(); !reflect.DeepEqual(got, []int{5}) {
+		t.Errorf("Plain click should replace selection, got %v", got)
+	}
+}
+
+func TestFileSelection_SelectAllAndInvert(t *testing.T) {
+	sel := newFileSelection()
+
+	sel.SelectAll(3)
+	if sel.Count() != 3 {
+		t.Errorf("Expected 3 selected items, got %d", sel.Count())
+	}
+
+	sel.Set(1)
+	sel.Invert(4)
+	if got := sel.Indices(); !reflect.DeepEqual(got, []int{0, 2, 3}) {
+		t.Errorf("Invert returned %v", got)
+	}
+
+	sel.Clear()
+	if sel.Count() != 0 || sel.IsSelected(0) {
+		t.Error("Clear should empty the selection")
+	}
+}
@@ -0,0 +1,372 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"fyne.io/fyne/v2"
+	"fyne.io/fyne/v2/container"
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/theme"
+	"fyne.io/fyne/v2/widget"
+)
+
+// session is one server connection with its own client, remote pane,
+// log and progress bar. It embeds the application for the shared local
+// pane, settings and window, so transfers started in one session keep
+// using its connection while the user browses another.
+type session struct {
+	*SFTPApp
+
+	client *SFTPGUIClient
+	title  string
+	tab    *container.TabItem
+
+	// Server the session is connected to
+	host string
+	user string
+	port int
+
+	// Remote files open for editing through this connection
+	edits *editManager
+
+	// Remote browser
+	remotePane    *filePane
+	remotePath    *completionEntry
+	completer     *dirCompleter
+	listings      *listingCache
+	navigation    *remoteNavigation
+	listing       *remoteListing
+	revalidation  context.CancelFunc // cancels the check of a cached listing
+	currentRemote string
+	remotePanel   fyne.CanvasObject
+
+	// Status and progress
+	progressBar *widget.ProgressBar
+	cancelBtn   *widget.Button
+	progress    *fyne.Container
+	logArea     *widget.Entry
+	logScroll   *container.Scroll
+	diskSpace   string
+	// diskSpaceGen identifies the latest free space lookup so that
+	// slower replies to earlier ones are dropped
+	diskSpaceGen int
+
+	// Long operations running in the background, by id, and the next id
+	opsMu  sync.Mutex
+	ops    map[int]context.CancelFunc
+	nextOp int
+}
+
+// newSession creates a session talking through client
+func (app *SFTPApp) newSession(client *SFTPGUIClient) *session {
+	s := &session{
+		SFTPApp: app,
+		client:  client,
+		ops:     make(map[int]context.CancelFunc),
+	}
+
+	s.edits = newEditManager(s.client, filepath.Join(os.TempDir(), "KAT-ftp-edit"))
+	s.edits.onSaved = s.onEditSaved
+	s.edits.onChange = func() {
+		app.do(func() {
+			if app.current == s {
+				app.showEditSessions()
+			}
+		})
+	}
+
+	s.progressBar = widget.NewProgressBar()
+	s.progressBar.Hide()
+	s.cancelBtn = widget.NewButtonWithIcon("Cancel", theme.CancelIcon(), s.cancelOperations)
+	s.cancelBtn.Hide()
+	s.progress = container.NewBorder(nil, nil, nil, s.cancelBtn, s.progressBar)
+
+	s.logArea = widget.NewMultiLineEntry()
+	s.logArea.SetPlaceHolder("Activity log will appear here...")
+	s.logArea.Wrapping = fyne.TextWrapWord
+	s.logScroll = container.NewScroll(s.logArea)
+	s.logScroll.SetMinSize(fyne.NewSize(0, 150))
+
+	s.remotePanel = s.createRemotePanel()
+	return s
+}
+
+// createRemotePanel creates the remote file browser shown in the tab of
+// the session
+func (s *session) createRemotePanel() fyne.CanvasObject {
+	s.completer = newDirCompleter(s.client.ListDirsContext)
+	s.listings = newListingCache()
+	s.remotePath = newCompletionEntry(func(text string) func(ctx context.Context) []string {
+		cwd := s.currentRemote
+		return func(ctx context.Context) []string { return s.completer.complete(ctx, text, cwd) }
+	}, s.do)
+	s.remotePath.SetPlaceHolder("Remote path")
+	s.remotePath.OnSubmitted = func(path string) {
+		s.navigateRemote(path, true)
+	}
+
+	s.remotePane = newFilePane(s.settings.Remote,
+		newPaneNav(s.onRemoteBack, s.onRemoteForward, s.onRemoteUp), s.drag)
+	s.remotePane.onSettingsChanged = func(settings paneSettings) {
+		s.settings.Remote = settings
+		s.saveSettings()
+	}
+	s.remotePane.onActivated = func() { s.activePane = s.remotePane }
+	s.remotePane.onSelectionChanged = func() {
+		if s.activePane == s.remotePane {
+			s.updatePreview()
+		}
+	}
+	s.remotePane.onRename = s.renameRemote
+	s.remotePane.onKey = s.onPaneKey
+	s.remotePane.onContextMenu = func() *fyne.Menu {
+		return s.contextMenu(s.remotePane)
+	}
+	s.remotePane.onDoubleClick = func(entry fileEntry) {
+		// Double-click enters directories
+		if entry.IsDir || entry.LinkDir {
+			s.navigateRemote(path.Join(s.currentRemote, entry.Name), true)
+		}
+	}
+
+	findBtn := widget.NewButtonWithIcon("Find", theme.SearchIcon(), s.showFindDialog)
+
+	return container.NewBorder(
+		container.NewVBox(
+			container.NewBorder(nil, nil, s.remotePane.nav.buttons(), findBtn, s.remotePath),
+			container.NewHScroll(s.remotePane.nav.crumbs),
+			s.remotePane.filter.content(),
+		),
+		s.remotePane.selectionBar(),
+		nil, nil,
+		s.remotePane.table,
+	)
+}
+
+// addSession shows a newly connected session in its own tab and selects it
+func (app *SFTPApp) addSession(s *session) {
+	s.tab = container.NewTabItemWithIcon(s.title, theme.ComputerIcon(), s.remotePanel)
+	app.sessions = append(app.sessions, s)
+
+	// Keep the New Session tab last
+	items := app.tabs.Items[:len(app.tabs.Items)-1]
+	app.tabs.Items = append(append([]*container.TabItem{}, items...), s.tab, app.newSessionTab)
+	app.tabs.Refresh()
+	app.tabs.Select(s.tab)
+	app.switchSession(s)
+}
+
+// removeSession closes the tab of a disconnected session
+func (app *SFTPApp) removeSession(s *session) {
+	for i, other := range app.sessions {
+		if other == s {
+			app.sessions = append(app.sessions[:i], app.sessions[i+1:]...)
+			break
+		}
+	}
+	app.tabs.Remove(s.tab)
+
+	next := app.home
+	if len(app.sessions) > 0 {
+		next = app.sessions[len(app.sessions)-1]
+		app.tabs.Select(next.tab)
+	} else {
+		app.tabs.Select(app.newSessionTab)
+	}
+	app.switchSession(next)
+	app.updateSessionControls()
+}
+
+// sessionForTab returns the session shown in a tab
+func (app *SFTPApp) sessionForTab(item *container.TabItem) *session {
+	for _, s := range app.sessions {
+		if s.tab == item {
+			return s
+		}
+	}
+	return app.home
+}
+
+// switchSession makes s the session the operations, log and footer act on
+func (app *SFTPApp) switchSession(s *session) {
+	if s == app.current {
+		return
+	}
+	previous := app.current
+	app.current = s
+
+	// Follow the tab switch when the remote pane was active
+	if previous != nil && app.activePane == previous.remotePane {
+		if s == app.home {
+			app.activePane = app.localPane
+		} else {
+			app.activePane = s.remotePane
+		}
+	}
+
+	app.progressArea.Objects = []fyne.CanvasObject{s.progress}
+	app.progressArea.Refresh()
+	app.logContent.Objects = []fyne.CanvasObject{s.logScroll}
+	app.logContent.Refresh()
+
+	app.updateSessionControls()
+	app.showEditSessions()
+	s.updatePreview()
+}
+
+// updateSessionControls enables the operations and sets the footer for
+// the current session
+func (app *SFTPApp) updateSessionControls() {
+	s := app.current
+	if s.client.IsConnected() && len(app.sessions) > 1 {
+		app.sendBtn.Enable()
+	} else {
+		app.sendBtn.Disable()
+	}
+
+	remoteButtons := []*widget.Button{
+		app.uploadBtn, app.downloadBtn, app.editBtn, app.dirCompBtn, app.sizeBtn, app.refreshBtn, app.footerDisconnect,
+	}
+	for _, btn := range remoteButtons {
+		if s.client.IsConnected() {
+			btn.Enable()
+		} else {
+			btn.Disable()
+		}
+	}
+
+	if s.client.IsConnected() {
+		app.connectionStatus.SetText("🔵 Connected to " + s.title)
+	} else if len(app.sessions) > 0 {
+		app.connectionStatus.SetText(fmt.Sprintf("⚪ %d session(s) open", len(app.sessions)))
+	} else {
+		app.connectionStatus.SetText("🔴 Disconnected")
+	}
+	app.diskSpaceLabel.SetText(s.diskSpace)
+}
+
+// onConnected starts browsing a newly connected session
+func (s *session) onConnected() {
+	s.logMessage("Connected successfully")
+
+	// Start in the remote working directory
+	s.currentRemote = ""
+	s.remotePane.nav.reset()
+	s.navigateRemote(".", false)
+
+	// Collapse the activity log to save space
+	if !s.isLogCollapsed {
+		s.toggleLogPanel()
+	}
+}
+
+// onDisconnected closes the tab of a session whose connection ended
+func (s *session) onDisconnected() {
+	s.stopRemoteListing()
+	s.cancelOperations()
+
+	// Edit sessions cannot upload without the connection. Copies with
+	// changes that were not uploaded are kept.
+	for _, kept := range s.edits.closeAll() {
+		s.home.logMessage(fmt.Sprintf("Kept unsaved edits in %s", kept))
+	}
+
+	s.home.logMessage(fmt.Sprintf("Disconnected from %s", s.title))
+	s.removeSession(s)
+
+	// Update bookmark selection state
+	s.bookmarkSelect.ClearSelected()
+	s.deleteBookmarkBtn.Disable()
+	s.quickConnectBtn.Disable()
+
+	// Expand the activity log when the last session is closed
+	if len(s.sessions) == 0 && s.isLogCollapsed {
+		s.toggleLogPanel()
+	}
+}
+
+// closeAllSessions disconnects every session, for example when quitting
+func (app *SFTPApp) closeAllSessions() {
+	for _, s := range append([]*session(nil), app.sessions...) {
+		s.cancelOperations()
+		s.edits.closeAll()
+		s.client.Disconnect()
+	}
+}
+
+// startOperation registers a long operation of the session and returns
+// the context it runs under, which the Cancel button next to the progress
+// bar cancels. done must be called once the operation has finished.
+func (s *session) startOperation() (ctx context.Context, done func()) {
+	ctx, cancel := context.WithCancel(context.Background())
+	s.opsMu.Lock()
+	id := s.nextOp
+	s.nextOp++
+	s.ops[id] = cancel
+	s.opsMu.Unlock()
+	s.do(s.updateCancelButton)
+
+	return ctx, func() {
+		cancel()
+		s.opsMu.Lock()
+		delete(s.ops, id)
+		s.opsMu.Unlock()
+		s.do(s.updateCancelButton)
+	}
+}
+
+// cancelOperations cancels every long operation of the session
+func (s *session) cancelOperations() {
+	s.opsMu.Lock()
+	defer s.opsMu.Unlock()
+	for _, cancel := range s.ops {
+		cancel()
+	}
+}
+
+// updateCancelButton shows the Cancel button while operations are running
+func (s *session) updateCancelButton() {
+	s.opsMu.Lock()
+	running := len(s.ops)
+	s.opsMu.Unlock()
+	if running > 0 {
+		s.cancelBtn.Show()
+	} else {
+		s.cancelBtn.Hide()
+	}
+}
+
+// The progress, log and error helpers below go through the dispatcher,
+// so they can be called from any goroutine.
+
+func (s *session) showProgress(message string) {
+	s.do(s.progressBar.Show)
+	s.logMessage(message)
+}
+
+func (s *session) hideProgress() {
+	s.do(s.progressBar.Hide)
+}
+
+// setProgress moves the progress bar of the session
+func (s *session) setProgress(value float64) {
+	s.do(func() { s.progressBar.SetValue(value) })
+}
+
+// logMessage adds a line to the log of the session
+func (s *session) logMessage(message string) {
+	timestamp := time.Now().Format("15:04:05")
+	logEntry := fmt.Sprintf("[%s] %s\n", timestamp, message)
+	s.do(func() { s.logArea.SetText(s.logArea.Text + logEntry) })
+}
+
+func (s *session) showError(message string) {
+	s.do(func() { dialog.ShowError(fmt.Errorf(message), s.window) })
+	s.logMessage("ERROR: " + message)
+}
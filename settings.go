@@ -0,0 +1,76 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+)
+
+// Settings holds the user preferences persisted between runs
+type Settings struct {
+	Local  paneSettings `json:"local"`
+	Remote paneSettings `json:"remote"`
+
+	// Recursive deletes above either threshold must be confirmed by
+	// typing the name of what is deleted
+	ConfirmDeleteItems int   `json:"confirm_delete_items"`
+	ConfirmDeleteBytes int64 `json:"confirm_delete_bytes"`
+
+	// FollowLinks makes transfers and properties act on symlink targets
+	// instead of the links themselves
+	FollowLinks bool `json:"follow_links"`
+
+	// Editor is the command used to edit remote files, for example
+	// "code --wait". The system default application is used when empty.
+	Editor string `json:"editor"`
+
+	// ShowPreview shows the file preview next to the file panes
+	ShowPreview bool `json:"show_preview"`
+}
+
+// paneSettings holds the view preferences of a single file pane
+type paneSettings struct {
+	Sort       sortSetting `json:"sort"`
+	ShowHidden bool        `json:"show_hidden"`
+}
+
+// defaultSettings returns the preferences used when none are saved
+func defaultSettings() Settings {
+	return Settings{
+		Local:  paneSettings{Sort: defaultSort},
+		Remote: paneSettings{Sort: defaultSort},
+
+		ConfirmDeleteItems: 100,
+		ConfirmDeleteBytes: 100 << 20,
+
+		FollowLinks: true,
+	}
+}
+
+func (app *SFTPApp) loadSettings() {
+	app.settings = defaultSettings()
+
+	data, err := os.ReadFile(app.settingsFile)
+	if err != nil {
+		// File doesn't exist or can't be read, keep the defaults
+		return
+	}
+
+	if err := json.Unmarshal(data, &app.settings); err != nil {
+		// Don't use logMessage here as UI isn't initialized yet
+		fmt.Printf("Warning: Error loading settings: %v\n", err)
+		app.settings = defaultSettings()
+	}
+}
+
+func (app *SFTPApp) saveSettings() {
+	data, err := json.MarshalIndent(app.settings, "", "  ")
+	if err != nil {
+		app.logMessage("Error saving settings: " + err.Error())
+		return
+	}
+
+	if err := os.WriteFile(app.settingsFile, data, 0600); err != nil {
+		app.logMessage("Error writing settings file: " + err.Error())
+	}
+}
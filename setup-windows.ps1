@@ -140,7 +140,7 @@ function Build-GUI {
     $env:CGO_ENABLED = "1"
 
     try {
-        go build -o sftp-client-gui.exe main.go app_icon.go
+        go build -o sftp-client-gui.exe .
         if ($LASTEXITCODE -eq 0) {
             Write-Host "✅ GUI Version built successfully!" -ForegroundColor Green
             Write-Host "   Executable: sftp-client-gui.exe" -ForegroundColor Gray
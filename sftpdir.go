@@ -0,0 +1,310 @@
+package main
+
+import (
+	"bufio"
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path"
+	"time"
+
+	"github.com/pkg/sftp"
+	"golang.org/x/crypto/ssh"
+)
+
+// SFTP version 3 packet types used to read folders
+const (
+	sshFxpInit    = 1
+	sshFxpVersion = 2
+	sshFxpClose   = 4
+	sshFxpOpendir = 11
+	sshFxpReaddir = 12
+	sshFxpStatus  = 101
+	sshFxpHandle  = 102
+	sshFxpName    = 104
+)
+
+// Attribute flags of SFTP version 3
+const (
+	sshFileXferAttrSize        = 0x1
+	sshFileXferAttrUIDGID      = 0x2
+	sshFileXferAttrPermissions = 0x4
+	sshFileXferAttrACModTime   = 0x8
+	sshFileXferAttrExtended    = 0x80000000
+)
+
+// sftpMaxPacket is the largest reply accepted from the server
+const sftpMaxPacket = 4 << 20
+
+var errMalformedPacket = errors.New("malformed SFTP packet")
+
+// sftpChannel is an SFTP subsystem session of its own on an SSH connection
+type sftpChannel struct {
+	io.Reader
+	io.WriteCloser
+	session *ssh.Session
+}
+
+func (ch *sftpChannel) Close() error {
+	return ch.session.Close()
+}
+
+// openSFTPChannel starts another SFTP subsystem on sshClient
+func openSFTPChannel(sshClient *ssh.Client) (io.ReadWriteCloser, error) {
+	session, err := sshClient.NewSession()
+	if err != nil {
+		return nil, err
+	}
+	stdin, err := session.StdinPipe()
+	if err != nil {
+		session.Close()
+		return nil, err
+	}
+	stdout, err := session.StdoutPipe()
+	if err != nil {
+		session.Close()
+		return nil, err
+	}
+	if err := session.RequestSubsystem("sftp"); err != nil {
+		session.Close()
+		return nil, err
+	}
+	return &sftpChannel{Reader: stdout, WriteCloser: stdin, session: session}, nil
+}
+
+// sftpDirReader reads folders over an SFTP session. Unlike pkg/sftp,
+// which returns a folder once the server has sent every name, it hands
+// over the names of each READDIR reply as it arrives.
+type sftpDirReader struct {
+	rw io.ReadWriteCloser
+	r  *bufio.Reader
+	id uint32
+}
+
+// newSFTPDirReader starts an SFTP version 3 session on rw
+func newSFTPDirReader(rw io.ReadWriteCloser) (*sftpDirReader, error) {
+	d := &sftpDirReader{rw: rw, r: bufio.NewReaderSize(rw, 64<<10)}
+	if err := d.send(sshFxpInit, binary.BigEndian.AppendUint32(nil, 3)); err != nil {
+		return nil, err
+	}
+	typ, _, err := d.receive()
+	if err != nil {
+		return nil, err
+	}
+	if typ != sshFxpVersion {
+		return nil, fmt.Errorf("unexpected SFTP packet type %d", typ)
+	}
+	return d, nil
+}
+
+// readDir reads dir, calling batch with the entries of every reply
+func (d *sftpDirReader) readDir(dir string, batch func([]os.FileInfo)) error {
+	typ, reply, err := d.request(sshFxpOpendir, dir)
+	if err != nil {
+		return err
+	}
+	if typ == sshFxpStatus {
+		return statusError(dir, reply)
+	}
+	if typ != sshFxpHandle {
+		return fmt.Errorf("unexpected SFTP packet type %d", typ)
+	}
+	dec := &sftpDecoder{b: reply}
+	handle := dec.string()
+	if dec.err != nil {
+		return dec.err
+	}
+	defer d.request(sshFxpClose, handle)
+
+	for {
+		typ, reply, err := d.request(sshFxpReaddir, handle)
+		if err != nil {
+			return err
+		}
+		switch typ {
+		case sshFxpStatus:
+			if err := statusError(dir, reply); err != io.EOF {
+				return err
+			}
+			return nil
+		case sshFxpName:
+			files, err := decodeNames(reply)
+			if err != nil {
+				return err
+			}
+			if len(files) > 0 {
+				batch(files)
+			}
+		default:
+			return fmt.Errorf("unexpected SFTP packet type %d", typ)
+		}
+	}
+}
+
+// request sends a request of type typ with a string argument and returns
+// the type and the payload after the id of the reply
+func (d *sftpDirReader) request(typ byte, arg string) (byte, []byte, error) {
+	d.id++
+	payload := binary.BigEndian.AppendUint32(nil, d.id)
+	payload = binary.BigEndian.AppendUint32(payload, uint32(len(arg)))
+	payload = append(payload, arg...)
+	if err := d.send(typ, payload); err != nil {
+		return 0, nil, err
+	}
+
+	replyType, reply, err := d.receive()
+	if err != nil {
+		return 0, nil, err
+	}
+	dec := &sftpDecoder{b: reply}
+	if id := dec.uint32(); dec.err != nil || id != d.id {
+		return 0, nil, errMalformedPacket
+	}
+	return replyType, dec.b, nil
+}
+
+func (d *sftpDirReader) send(typ byte, payload []byte) error {
+	packet := make([]byte, 0, 5+len(payload))
+	packet = binary.BigEndian.AppendUint32(packet, uint32(1+len(payload)))
+	packet = append(packet, typ)
+	packet = append(packet, payload...)
+	_, err := d.rw.Write(packet)
+	return err
+}
+
+func (d *sftpDirReader) receive() (byte, []byte, error) {
+	var header [5]byte
+	if _, err := io.ReadFull(d.r, header[:]); err != nil {
+		return 0, nil, err
+	}
+	length := binary.BigEndian.Uint32(header[:4])
+	if length < 1 || length > sftpMaxPacket {
+		return 0, nil, errMalformedPacket
+	}
+	payload := make([]byte, length-1)
+	if _, err := io.ReadFull(d.r, payload); err != nil {
+		return 0, nil, err
+	}
+	return header[4], payload, nil
+}
+
+// statusError turns a STATUS reply into an error. The end of a listing
+// is io.EOF and success is nil.
+func statusError(dir string, reply []byte) error {
+	dec := &sftpDecoder{b: reply}
+	code := dec.uint32()
+	msg := dec.string()
+	if dec.err != nil {
+		return dec.err
+	}
+	switch code {
+	case 0:
+		return nil
+	case 1:
+		return io.EOF
+	case 2:
+		return &os.PathError{Op: "opendir", Path: dir, Err: os.ErrNotExist}
+	case 3:
+		return &os.PathError{Op: "opendir", Path: dir, Err: os.ErrPermission}
+	}
+	return fmt.Errorf("sftp: %s (code %d)", msg, code)
+}
+
+// decodeNames decodes a NAME reply, leaving out . and ..
+func decodeNames(reply []byte) ([]os.FileInfo, error) {
+	dec := &sftpDecoder{b: reply}
+	count := dec.uint32()
+	var files []os.FileInfo
+	for i := uint32(0); i < count && dec.err == nil; i++ {
+		name := dec.string()
+		dec.string() // long name
+		stat := dec.attrs()
+		if name == "." || name == ".." {
+			continue
+		}
+		files = append(files, &sftpFileInfo{name: path.Base(name), stat: stat})
+	}
+	if dec.err != nil {
+		return nil, dec.err
+	}
+	return files, nil
+}
+
+// sftpDecoder reads the fields of an SFTP packet. Reading past the end
+// sets err.
+type sftpDecoder struct {
+	b   []byte
+	err error
+}
+
+func (d *sftpDecoder) uint32() uint32 {
+	if len(d.b) < 4 {
+		d.err = errMalformedPacket
+		return 0
+	}
+	v := binary.BigEndian.Uint32(d.b)
+	d.b = d.b[4:]
+	return v
+}
+
+func (d *sftpDecoder) uint64() uint64 {
+	if len(d.b) < 8 {
+		d.err = errMalformedPacket
+		return 0
+	}
+	v := binary.BigEndian.Uint64(d.b)
+	d.b = d.b[8:]
+	return v
+}
+
+func (d *sftpDecoder) string() string {
+	n := d.uint32()
+	if d.err != nil || uint64(len(d.b)) < uint64(n) {
+		d.err = errMalformedPacket
+		return ""
+	}
+	s := string(d.b[:n])
+	d.b = d.b[n:]
+	return s
+}
+
+func (d *sftpDecoder) attrs() *sftp.FileStat {
+	flags := d.uint32()
+	stat := &sftp.FileStat{}
+	if flags&sshFileXferAttrSize != 0 {
+		stat.Size = d.uint64()
+	}
+	if flags&sshFileXferAttrUIDGID != 0 {
+		stat.UID = d.uint32()
+		stat.GID = d.uint32()
+	}
+	if flags&sshFileXferAttrPermissions != 0 {
+		stat.Mode = d.uint32()
+	}
+	if flags&sshFileXferAttrACModTime != 0 {
+		stat.Atime = d.uint32()
+		stat.Mtime = d.uint32()
+	}
+	if flags&sshFileXferAttrExtended != 0 {
+		for n := d.uint32(); n > 0 && d.err == nil; n-- {
+			stat.Extended = append(stat.Extended, sftp.StatExtended{ExtType: d.string(), ExtData: d.string()})
+		}
+	}
+	return stat
+}
+
+// sftpFileInfo is an entry of a READDIR reply. Sys returns the
+// *sftp.FileStat, as for entries read through pkg/sftp.
+type sftpFileInfo struct {
+	name string
+	stat *sftp.FileStat
+}
+
+func (fi *sftpFileInfo) Name() string       { return fi.name }
+func (fi *sftpFileInfo) Size() int64        { return int64(fi.stat.Size) }
+func (fi *sftpFileInfo) Mode() os.FileMode  { return fi.stat.FileMode() }
+func (fi *sftpFileInfo) ModTime() time.Time { return fi.stat.ModTime() }
+func (fi *sftpFileInfo) IsDir() bool        { return fi.Mode().IsDir() }
+func (fi *sftpFileInfo) Sys() interface{}   { return fi.stat }
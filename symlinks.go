@@ -0,0 +1,91 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+
+	"fyne.io/fyne/v2/dialog"
+	"fyne.io/fyne/v2/widget"
+)
+
+// Symlink creates a remote symbolic link at linkPath pointing to target.
+// target is stored as given, so relative targets are resolved from the
+// directory of the link.
+func (c *SFTPGUIClient) Symlink(target, linkPath string) error {
+	exists, err := c.Exists(linkPath)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("%w: %s", errTargetExists, linkPath)
+	}
+
+	return c.sftpClient.Symlink(target, linkPath)
+}
+
+// ReadLink returns the target of a remote symbolic link
+func (c *SFTPGUIClient) ReadLink(linkPath string) (string, error) {
+	if !c.IsConnected() {
+		return "", fmt.Errorf("not connected")
+	}
+
+	return c.sftpClient.ReadLink(linkPath)
+}
+
+// onCreateSymlink asks for a target and a name and creates a symbolic
+// link in the current directory of the active pane. The target defaults
+// to the selected entry.
+func (s *session) onCreateSymlink() {
+	remote := s.activePane == s.remotePane
+	if remote && !s.client.IsConnected() {
+		return
+	}
+
+	targetEntry := widget.NewEntry()
+	targetEntry.SetPlaceHolder("Path the link points to")
+	nameEntry := widget.NewEntry()
+	nameEntry.SetPlaceHolder("Name of the new link")
+	if entries := s.activePane.selectedEntries(); len(entries) == 1 {
+		targetEntry.SetText(entries[0].Name)
+		nameEntry.SetText(entries[0].Name + "-link")
+	}
+
+	dialog.ShowForm("Create Symlink", "Create", "Cancel",
+		[]*widget.FormItem{
+			widget.NewFormItem("Target", targetEntry),
+			widget.NewFormItem("Link Name", nameEntry),
+		},
+		func(confirmed bool) {
+			if !confirmed || targetEntry.Text == "" || nameEntry.Text == "" {
+				return
+			}
+
+			target, name := targetEntry.Text, nameEntry.Text
+			if !remote {
+				if err := os.Symlink(target, filepath.Join(s.currentLocal, name)); err != nil {
+					s.showError(fmt.Sprintf("Create symlink failed: %v", err))
+					return
+				}
+				s.logMessage(fmt.Sprintf("Created symlink: %s → %s", name, target))
+				s.updateLocalFiles()
+				return
+			}
+
+			// The server is asked in the background
+			linkPath := path.Join(s.currentRemote, name)
+			_, done := s.startOperation()
+			go func() {
+				defer done()
+				err := s.client.Symlink(target, linkPath)
+				s.remoteChanged(linkPath)
+				if err != nil {
+					s.showError(fmt.Sprintf("Create symlink failed: %v", err))
+					return
+				}
+				s.logMessage(fmt.Sprintf("Created symlink: %s → %s", name, target))
+				s.do(s.updateRemoteFiles)
+			}()
+		}, s.window)
+}
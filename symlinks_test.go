@@ -0,0 +1,74 @@
+package main
+
+import (
+	"errors"
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestSFTPGUIClient_Symlink(t *testing.T) {
+	client, root := newTestClient(t)
+	writeTestFiles(t, root, map[string]string{
+		"dir/a.txt": "a",
+	})
+
+	// The test server resolves targets against its working directory, so
+	// use absolute targets
+	if err := client.Symlink(root+"/dir", root+"/dir-link"); err != nil {
+		t.Fatalf("Symlink failed: %v", err)
+	}
+	if err := client.Symlink(root+"/missing", root+"/broken"); err != nil {
+		t.Fatalf("Symlink failed: %v", err)
+	}
+	if err := client.Symlink(root+"/dir", root+"/dir-link"); !errors.Is(err, errTargetExists) {
+		t.Errorf("Expected errTargetExists, got %v", err)
+	}
+
+	target, err := client.ReadLink(root + "/dir-link")
+	if err != nil || target != root+"/dir" {
+		t.Errorf("Expected target %s/dir, got %q, %v", root, target, err)
+	}
+	if target, _ := os.Readlink(filepath.Join(root, "dir-link")); target != filepath.Join(root, "dir") {
+		t.Errorf("Link created with the wrong target %q", target)
+	}
+
+	entries, err := client.GetFiles(root)
+	if err != nil {
+		t.Fatalf("GetFiles failed: %v", err)
+	}
+	found := make(map[string]fileEntry)
+	for _, entry := range entries {
+		found[entry.Name] = entry
+	}
+	if link := found["dir-link"]; !link.IsSymlink() || !link.LinkDir || link.LinkBroken {
+		t.Errorf("Expected a working link to a directory, got %+v", link)
+	}
+	if link := found["broken"]; !link.IsSymlink() || !link.LinkBroken {
+		t.Errorf("Expected a broken link, got %+v", link)
+	}
+}
+
+func TestFileEntryLinkDisplay(t *testing.T) {
+	link := fileEntry{Name: "l", Mode: os.ModeSymlink}
+	link.setLink("dir", fileInfoStub{dir: true}, nil)
+	if got := link.DisplayName(); got != "📁 🔗 l → dir" {
+		t.Errorf("Unexpected display name %q", got)
+	}
+	if link.SizeText() != "" {
+		t.Error("Links to directories should have no size")
+	}
+
+	link.setLink("gone", nil, os.ErrNotExist)
+	if got := link.DisplayName(); got != "⚠️ 🔗 l → gone (broken)" {
+		t.Errorf("Unexpected display name %q", got)
+	}
+}
+
+// fileInfoStub is a minimal os.FileInfo
+type fileInfoStub struct {
+	os.FileInfo
+	dir bool
+}
+
+func (f fileInfoStub) IsDir() bool { return f.dir }
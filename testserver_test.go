@@ -0,0 +1,98 @@
+package main
+
+import (
+	"io"
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/pkg/sftp"
+)
+
+// newTestClient returns a connected client talking to an in-process SFTP
+// server that serves a fresh temporary directory, along with that directory.
+func newTestClient(t *testing.T) (*SFTPGUIClient, string) {
+	t.Helper()
+	root := t.TempDir()
+
+	clientReader, serverWriter := io.Pipe()
+	serverReader, clientWriter := io.Pipe()
+
+	server, err := sftp.NewServer(struct {
+		io.Reader
+		io.WriteCloser
+	}{serverReader, serverWriter}, sftp.WithServerWorkingDirectory(root))
+	if err != nil {
+		t.Fatalf("Failed to create test server: %v", err)
+	}
+	go server.Serve()
+
+	sftpClient, err := sftp.NewClientPipe(clientReader, clientWriter)
+	if err != nil {
+		t.Fatalf("Failed to create test client: %v", err)
+	}
+
+	client := NewSFTPGUIClient()
+	client.sftpClient = sftpClient
+	client.openChannel = func() (io.ReadWriteCloser, error) { return openTestChannel(root) }
+	client.connected = true
+
+	t.Cleanup(func() {
+		// Closing the server first ends the client's receive loop
+		server.Close()
+		client.Disconnect()
+	})
+
+	return client, root
+}
+
+// testChannel is an SFTP session of its own with an in-process server
+type testChannel struct {
+	io.Reader
+	io.Writer
+	server *sftp.Server
+	pipes  []io.Closer
+}
+
+func (ch *testChannel) Close() error {
+	for _, p := range ch.pipes {
+		p.Close()
+	}
+	return ch.server.Close()
+}
+
+// openTestChannel starts another in-process SFTP server on root, as the
+// client opens for reading folders
+func openTestChannel(root string) (io.ReadWriteCloser, error) {
+	clientReader, serverWriter := io.Pipe()
+	serverReader, clientWriter := io.Pipe()
+	server, err := sftp.NewServer(struct {
+		io.Reader
+		io.WriteCloser
+	}{serverReader, serverWriter}, sftp.WithServerWorkingDirectory(root))
+	if err != nil {
+		return nil, err
+	}
+	go server.Serve()
+	return &testChannel{
+		Reader: clientReader,
+		Writer: clientWriter,
+		server: server,
+		pipes:  []io.Closer{clientReader, clientWriter, serverReader, serverWriter},
+	}, nil
+}
+
+// writeTestFiles creates files below root, keyed by slash-separated
+// relative path, creating parent directories as needed.
+func writeTestFiles(t *testing.T, root string, files map[string]string) {
+	t.Helper()
+	for name, content := range files {
+		p := filepath.Join(root, filepath.FromSlash(name))
+		if err := os.MkdirAll(filepath.Dir(p), 0755); err != nil {
+			t.Fatal(err)
+		}
+		if err := os.WriteFile(p, []byte(content), 0644); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
@@ -0,0 +1,172 @@
+//go:build linux
+// +build linux
+
+package main
+
+import (
+	"errors"
+	"fmt"
+	"net/url"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"syscall"
+	"time"
+)
+
+// trashSupported reports whether local deletes can go to the trash
+const trashSupported = true
+
+// moveToTrash moves a local file or directory to the trash following the
+// freedesktop.org Trash specification. Files on the same device as the
+// home directory go to the home trash, others to the trash directory at
+// the top of their mount.
+func moveToTrash(p string) error {
+	abs, err := filepath.Abs(p)
+	if err != nil {
+		return err
+	}
+	info, err := os.Lstat(abs)
+	if err != nil {
+		return err
+	}
+
+	homeTrash := homeTrashDir()
+	if sameDevice(info, filepath.Dir(homeTrash)) {
+		if err := os.MkdirAll(homeTrash, 0700); err == nil {
+			return trashInto(homeTrash, abs, abs)
+		}
+	}
+
+	topdir, err := mountTop(abs)
+	if err != nil {
+		return err
+	}
+	trashDir, err := topdirTrashDir(topdir)
+	if err != nil {
+		return err
+	}
+	rel, err := filepath.Rel(topdir, abs)
+	if err != nil {
+		return err
+	}
+	return trashInto(trashDir, abs, rel)
+}
+
+// homeTrashDir returns $XDG_DATA_HOME/Trash
+func homeTrashDir() string {
+	dataHome := os.Getenv("XDG_DATA_HOME")
+	if dataHome == "" {
+		home, _ := os.UserHomeDir()
+		dataHome = filepath.Join(home, ".local", "share")
+	}
+	return filepath.Join(dataHome, "Trash")
+}
+
+// topdirTrashDir returns the trash directory of the mount at topdir,
+// preferring an administrator-created $topdir/.Trash/$uid
+func topdirTrashDir(topdir string) (string, error) {
+	uid := strconv.Itoa(os.Getuid())
+
+	shared := filepath.Join(topdir, ".Trash")
+	if info, err := os.Lstat(shared); err == nil && info.IsDir() && info.Mode()&os.ModeSticky != 0 {
+		dir := filepath.Join(shared, uid)
+		if err := os.MkdirAll(dir, 0700); err == nil {
+			return dir, nil
+		}
+	}
+
+	dir := filepath.Join(topdir, ".Trash-"+uid)
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		return "", fmt.Errorf("no usable trash on this device: %v", err)
+	}
+	return dir, nil
+}
+
+// trashInto moves abs into trashDir, recording originalPath in its info file
+func trashInto(trashDir, abs, originalPath string) error {
+	filesDir := filepath.Join(trashDir, "files")
+	infoDir := filepath.Join(trashDir, "info")
+	if err := os.MkdirAll(filesDir, 0700); err != nil {
+		return err
+	}
+	if err := os.MkdirAll(infoDir, 0700); err != nil {
+		return err
+	}
+
+	info := fmt.Sprintf("[Trash Info]\nPath=%s\nDeletionDate=%s\n",
+		(&url.URL{Path: originalPath}).EscapedPath(), time.Now().Format("2006-01-02T15:04:05"))
+
+	// Creating the info file exclusively reserves the name in the trash
+	base := filepath.Base(abs)
+	ext := filepath.Ext(base)
+	stem := strings.TrimSuffix(base, ext)
+	for n := 1; ; n++ {
+		name := base
+		if n > 1 {
+			name = fmt.Sprintf("%s.%d%s", stem, n, ext)
+		}
+		infoPath := filepath.Join(infoDir, name+".trashinfo")
+		f, err := os.OpenFile(infoPath, os.O_WRONLY|os.O_CREATE|os.O_EXCL, 0600)
+		if errors.Is(err, os.ErrExist) {
+			continue
+		}
+		if err != nil {
+			return err
+		}
+		_, err = f.WriteString(info)
+		if closeErr := f.Close(); err == nil {
+			err = closeErr
+		}
+		if err == nil {
+			err = os.Rename(abs, filepath.Join(filesDir, name))
+		}
+		if err != nil {
+			os.Remove(infoPath)
+			return fmt.Errorf("failed to move to trash: %v", err)
+		}
+		return nil
+	}
+}
+
+// sameDevice reports whether info lives on the same device as dir
+func sameDevice(info os.FileInfo, dir string) bool {
+	for dir != "/" {
+		if _, err := os.Stat(dir); err == nil {
+			break
+		}
+		// The trash may not exist yet, compare with its closest parent
+		dir = filepath.Dir(dir)
+	}
+	dirInfo, err := os.Stat(dir)
+	if err != nil {
+		return false
+	}
+	return deviceOf(info) == deviceOf(dirInfo)
+}
+
+// mountTop returns the top directory of the mount containing abs
+func mountTop(abs string) (string, error) {
+	info, err := os.Lstat(abs)
+	if err != nil {
+		return "", err
+	}
+	dev := deviceOf(info)
+	dir := filepath.Dir(abs)
+	for dir != "/" {
+		parent, err := os.Stat(filepath.Dir(dir))
+		if err != nil || deviceOf(parent) != dev {
+			return dir, nil
+		}
+		dir = filepath.Dir(dir)
+	}
+	return dir, nil
+}
+
+func deviceOf(info os.FileInfo) uint64 {
+	if stat, ok := info.Sys().(*syscall.Stat_t); ok {
+		return uint64(stat.Dev)
+	}
+	return 0
+}
@@ -0,0 +1,53 @@
+//go:build linux
+// +build linux
+
+package main
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+func TestMoveToTrash(t *testing.T) {
+	root := t.TempDir()
+	t.Setenv("XDG_DATA_HOME", filepath.Join(root, "data"))
+	writeTestFiles(t, root, map[string]string{
+		"work/a b.txt":   "first",
+		"work/dir/c.txt": "c",
+		"other/a b.txt":  "second",
+	})
+
+	for _, p := range []string{"work/a b.txt", "work/dir", "other/a b.txt"} {
+		if err := moveToTrash(filepath.Join(root, p)); err != nil {
+			t.Fatalf("moveToTrash(%s) failed: %v", p, err)
+		}
+		if _, err := os.Lstat(filepath.Join(root, p)); !os.IsNotExist(err) {
+			t.Errorf("%s should no longer exist", p)
+		}
+	}
+
+	trash := filepath.Join(root, "data", "Trash")
+	if data, _ := os.ReadFile(filepath.Join(trash, "files", "a b.txt")); string(data) != "first" {
+		t.Errorf("Expected trashed content, got %q", data)
+	}
+	if data, _ := os.ReadFile(filepath.Join(trash, "files", "a b.2.txt")); string(data) != "second" {
+		t.Errorf("Expected the second file under a unique name, got %q", data)
+	}
+	if _, err := os.Stat(filepath.Join(trash, "files", "dir", "c.txt")); err != nil {
+		t.Errorf("Expected the trashed directory with its contents: %v", err)
+	}
+
+	info, err := os.ReadFile(filepath.Join(trash, "info", "a b.txt.trashinfo"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "Path=" + filepath.ToSlash(filepath.Join(root, "work")) + "/a%20b.txt\n"
+	if !strings.HasPrefix(string(info), "[Trash Info]\n") || !strings.Contains(string(info), want) {
+		t.Errorf("Unexpected trash info:\n%s", info)
+	}
+	if !strings.Contains(string(info), "DeletionDate=") {
+		t.Errorf("Missing deletion date:\n%s", info)
+	}
+}
@@ -0,0 +1,14 @@
+//go:build !linux
+// +build !linux
+
+package main
+
+import "fmt"
+
+// trashSupported reports whether local deletes can go to the trash
+const trashSupported = false
+
+// moveToTrash is only implemented for the freedesktop.org trash on Linux
+func moveToTrash(p string) error {
+	return fmt.Errorf("moving to the trash is not supported on this platform")
+}